@@ -0,0 +1,365 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/models"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+const usage = `Usage:
+  go-webcrawler                 Start the web server on :8080
+  go-webcrawler crawl <url>...  Crawl URLs and print the results
+
+Crawl flags:
+`
+
+type crawlFlags struct {
+	format       string
+	depth        int
+	maxPages     int
+	scope        string
+	input        string
+	ignoreRobots bool
+
+	config    string
+	userAgent string
+	headers   headerFlags
+	timeout   time.Duration
+	redirects int
+	proxy     string
+	insecure  bool
+	caBundle  string
+	linkScope string
+	render    bool
+	chromeURL string
+	waitFor   string
+	analyzers string
+	disabled  string
+}
+
+// headerFlags collects repeated --header flags
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+// Run executes the command line in args (without the program name) and returns the exit code
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "crawl" {
+		fmt.Fprint(stderr, usage)
+		newFlagSet(&crawlFlags{}, stderr).PrintDefaults()
+		return ExitUsage
+	}
+
+	flags := &crawlFlags{}
+	fs := newFlagSet(flags, stderr)
+
+	urls, err := parseArgs(fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		return ExitUsage
+	}
+
+	if err := validateFlags(flags); err != nil {
+		fmt.Fprintf(stderr, "go-webcrawler: %v\n", err)
+		return ExitUsage
+	}
+
+	opts, err := crawlerOptions(flags)
+	if err != nil {
+		fmt.Fprintf(stderr, "go-webcrawler: %v\n", err)
+		return ExitUsage
+	}
+
+	if flags.input != "" {
+		inputURLs, err := readInput(flags.input, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "go-webcrawler: %v\n", err)
+			return ExitUsage
+		}
+		urls = append(urls, inputURLs...)
+	}
+
+	if len(urls) == 0 {
+		fmt.Fprintln(stderr, "go-webcrawler: no URLs given, pass them as arguments or with --input")
+		return ExitUsage
+	}
+
+	results := crawl(ctx, urls, flags, opts)
+
+	if err := writeResults(stdout, flags.format, results); err != nil {
+		fmt.Fprintf(stderr, "go-webcrawler: %v\n", err)
+		return ExitFailure
+	}
+
+	for _, result := range results {
+		if !result.Success {
+			return ExitFailure
+		}
+	}
+	return ExitOK
+}
+
+func newFlagSet(flags *crawlFlags, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("crawl", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	fs.StringVar(&flags.format, "format", "table", "output format: json, table or csv")
+	fs.IntVar(&flags.depth, "depth", 0, "follow internal links up to this depth, 0 crawls the given pages only")
+	fs.IntVar(&flags.maxPages, "max-pages", crawler.DefaultMaxPages, "maximum pages per site when --depth is set")
+	fs.StringVar(&flags.scope, "scope", string(crawler.ScopeHost), "links to follow when --depth is set: host, subdomains, domain or prefix")
+	fs.StringVar(&flags.input, "input", "", "read URLs from this file, one per line, - for stdin")
+	fs.BoolVar(&flags.ignoreRobots, "ignore-robots", false, "ignore robots.txt, only for sites you own")
+	fs.StringVar(&flags.config, "config", "", "JSON config file with crawler options (default $CRAWLER_CONFIG)")
+	fs.StringVar(&flags.userAgent, "user-agent", "", "User-Agent header to send")
+	fs.Var(&flags.headers, "header", "extra \"Name: value\" header, can be repeated")
+	fs.DurationVar(&flags.timeout, "timeout", 0, "total timeout per page, like 10s")
+	fs.IntVar(&flags.redirects, "max-redirects", 0, "redirects to follow per page, -1 follows none (default 10)")
+	fs.StringVar(&flags.proxy, "proxy", "", "http://, https:// or socks5:// proxy URL")
+	fs.BoolVar(&flags.insecure, "insecure", false, "skip TLS certificate verification")
+	fs.StringVar(&flags.caBundle, "ca-bundle", "", "PEM file with extra trusted certificate authorities")
+	fs.BoolVar(&flags.render, "render", false, "render pages in headless Chrome before analyzing them")
+	fs.StringVar(&flags.chromeURL, "chrome-url", "", "DevTools endpoint of Chrome for --render (default "+crawler.DefaultChromeURL+")")
+	fs.StringVar(&flags.waitFor, "wait-selector", "", "with --render, wait for this CSS selector instead of network idle")
+	fs.StringVar(&flags.linkScope, "link-scope", "", "links counted as internal: host, subdomains or domain (default domain)")
+	fs.StringVar(&flags.analyzers, "analyzers", "", "comma separated analyzers to run, all by default: "+strings.Join(crawler.DefaultRegistry.Names(), ", "))
+	fs.StringVar(&flags.disabled, "disable-analyzers", "", "comma separated analyzers to skip")
+
+	return fs
+}
+
+// parseArgs allows flags before and after the URLs
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var urls []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return urls, nil
+		}
+		urls = append(urls, args[0])
+		args = args[1:]
+	}
+}
+
+func validateFlags(flags *crawlFlags) error {
+	switch flags.format {
+	case "json", "table", "csv":
+	default:
+		return fmt.Errorf("unknown format %q, use json, table or csv", flags.format)
+	}
+
+	switch crawler.Scope(flags.scope) {
+	case crawler.ScopeHost, crawler.ScopeSubdomains, crawler.ScopeDomain, crawler.ScopePrefix:
+	default:
+		return fmt.Errorf("unknown scope %q, use host, subdomains, domain or prefix", flags.scope)
+	}
+
+	if flags.depth < 0 {
+		return fmt.Errorf("depth must not be negative")
+	}
+	return nil
+}
+
+// crawlerOptions loads the config file and environment, then applies the flags on top.
+// Without --config the file named by CRAWLER_CONFIG is used, like the server does
+func crawlerOptions(flags *crawlFlags) (crawler.Options, error) {
+	config := flags.config
+	if config == "" {
+		config = os.Getenv(crawler.ConfigEnv)
+	}
+	opts, err := crawler.LoadOptions(config)
+	if err != nil {
+		return opts, err
+	}
+
+	headers, err := crawler.ParseHeaders(flags.headers)
+	if err != nil {
+		return opts, err
+	}
+
+	ov := crawler.Overrides{Headers: headers}
+	if flags.userAgent != "" {
+		ov.UserAgent = &flags.userAgent
+	}
+	if flags.timeout > 0 {
+		timeout := crawler.Duration(flags.timeout)
+		ov.Timeout = &timeout
+	}
+	if flags.redirects != 0 {
+		ov.MaxRedirects = &flags.redirects
+	}
+	if flags.proxy != "" {
+		ov.ProxyURL = &flags.proxy
+	}
+	if flags.insecure {
+		ov.InsecureSkipVerify = &flags.insecure
+	}
+	if flags.caBundle != "" {
+		ov.CABundle = &flags.caBundle
+	}
+	if flags.ignoreRobots {
+		ov.IgnoreRobots = &flags.ignoreRobots
+	}
+	if flags.render {
+		ov.Render = &flags.render
+	}
+	if flags.chromeURL != "" {
+		ov.ChromeURL = &flags.chromeURL
+	}
+	if flags.waitFor != "" {
+		ov.RenderWaitSelector = &flags.waitFor
+	}
+	if flags.linkScope != "" {
+		scope := crawler.Scope(flags.linkScope)
+		ov.LinkScope = &scope
+	}
+	if flags.analyzers != "" {
+		ov.Analyzers = crawler.SplitList(flags.analyzers)
+	}
+	if flags.disabled != "" {
+		ov.DisabledAnalyzers = crawler.SplitList(flags.disabled)
+	}
+
+	opts = opts.Apply(ov)
+	// The URLs are crawled one after the other, but a host shouldn't notice where one ends
+	opts.Limiter = crawler.NewHostLimiter(opts)
+	return opts, opts.Validate()
+}
+
+func readInput(path string, stdin io.Reader) ([]string, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+func crawl(ctx context.Context, urls []string, flags *crawlFlags, opts crawler.Options) []models.CrawlResult {
+	var results []models.CrawlResult
+	for _, url := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if !crawler.IsValidURL(url) {
+			results = append(results, models.CrawlResult{
+				URL:   url,
+				Error: "Invalid URL",
+			})
+			continue
+		}
+
+		if flags.depth == 0 {
+			results = append(results, crawler.CrawlURLWithOptions(ctx, url, opts))
+			continue
+		}
+
+		site := crawler.CrawlSite(ctx, url, crawler.SiteOptions{
+			Options:  opts,
+			MaxDepth: flags.depth,
+			MaxPages: flags.maxPages,
+			Scope:    crawler.Scope(flags.scope),
+		})
+		results = append(results, site.Pages...)
+	}
+	return results
+}
+
+func writeResults(w io.Writer, format string, results []models.CrawlResult) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case "csv":
+		return writeCSV(w, results)
+	default:
+		return writeTable(w, results)
+	}
+}
+
+var columns = []string{"url", "depth", "status_code", "title", "html_version", "internal_links", "external_links", "inaccessible_links", "login_form", "error"}
+
+func row(result models.CrawlResult) []string {
+	return []string{
+		result.URL,
+		strconv.Itoa(result.Depth),
+		strconv.Itoa(result.StatusCode),
+		result.Title,
+		result.HTMLVersion,
+		strconv.Itoa(result.InternalLinks),
+		strconv.Itoa(result.ExternalLinks),
+		strconv.Itoa(result.InaccessibleLinks),
+		strconv.FormatBool(result.HasLoginForm),
+		result.Error,
+	}
+}
+
+func writeCSV(w io.Writer, results []models.CrawlResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write(columns)
+	for _, result := range results {
+		cw.Write(row(result))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeTable(w io.Writer, results []models.CrawlResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	// Tabs and line breaks inside a cell would break the table layout
+	cleaner := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	for _, result := range results {
+		cells := row(result)
+		for i, cell := range cells {
+			cells[i] = cleaner.Replace(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
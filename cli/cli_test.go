@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-webcrawler/models"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestSite() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Home</title></head><body><a href="/about">About</a></body></html>`)
+		case "/about":
+			fmt.Fprint(w, `<html><head><title>About</title></head></html>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func run(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_JSON(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	code, stdout, stderr := run("", "crawl", server.URL, "--format", "json")
+
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+
+	var results []models.CrawlResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Home" {
+		t.Errorf("Expected one result titled 'Home', got %+v", results)
+	}
+}
+
+func TestRun_Analyzers(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	code, stdout, stderr := run("", "crawl", server.URL, "--format", "json", "--analyzers", "title, links", "--disable-analyzers", "links")
+
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+
+	var results []models.CrawlResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Home" || results[0].HTMLVersion != "" || results[0].Links != nil {
+		t.Errorf("Expected only the title analyzer to run, got %+v", results)
+	}
+}
+
+func TestRun_Depth(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	code, stdout, stderr := run("", "crawl", "--format=csv", "--depth", "1", server.URL)
+
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV output: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d rows", len(records))
+	}
+	if records[0][0] != "url" || records[2][3] != "About" {
+		t.Errorf("Unexpected CSV output: %v", records)
+	}
+}
+
+func TestRun_Batch(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	os.WriteFile(path, []byte("# comment\n"+server.URL+"/about\n\n"), 0600)
+
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		rows  int
+	}{
+		{"From file", "", []string{"crawl", "--input", path}, 1},
+		{"From stdin", server.URL + "\n" + server.URL + "/about\n", []string{"crawl", "--input", "-"}, 2},
+		{"File and arguments", "", []string{"crawl", server.URL, "--input", path}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := run(tt.stdin, tt.args...)
+
+			if code != ExitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+			}
+
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			if len(lines) != tt.rows+1 {
+				t.Errorf("Expected header and %d rows, got %q", tt.rows, stdout)
+			}
+		})
+	}
+}
+
+func TestRun_Headers(t *testing.T) {
+	var userAgent, custom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			userAgent = r.Header.Get("User-Agent")
+			custom = r.Header.Get("X-Custom")
+		}
+		fmt.Fprint(w, `<html><head><title>Headers</title></head></html>`)
+	}))
+	defer server.Close()
+
+	code, _, stderr := run("", "crawl", server.URL, "--user-agent", "cli-agent", "--header", "X-Custom: yes")
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+
+	if userAgent != "cli-agent" {
+		t.Errorf("Expected user agent from --user-agent, got %q", userAgent)
+	}
+	if custom != "yes" {
+		t.Errorf("Expected X-Custom header from --header, got %q", custom)
+	}
+}
+
+func TestRun_ConfigEnv(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			userAgent = r.Header.Get("User-Agent")
+		}
+		fmt.Fprint(w, `<html><head><title>Config</title></head></html>`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	envConfig := filepath.Join(dir, "env.json")
+	os.WriteFile(envConfig, []byte(`{"user_agent": "env-agent"}`), 0600)
+	flagConfig := filepath.Join(dir, "flag.json")
+	os.WriteFile(flagConfig, []byte(`{"user_agent": "flag-agent"}`), 0600)
+	t.Setenv("CRAWLER_CONFIG", envConfig)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"From CRAWLER_CONFIG", []string{"crawl", server.URL}, "env-agent"},
+		{"--config wins", []string{"crawl", server.URL, "--config", flagConfig}, "flag-agent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := run("", tt.args...)
+			if code != ExitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+			}
+			if userAgent != tt.want {
+				t.Errorf("Expected user agent %q, got %q", tt.want, userAgent)
+			}
+		})
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"No command", []string{}, ExitUsage},
+		{"Unknown command", []string{"fetch", server.URL}, ExitUsage},
+		{"No URLs", []string{"crawl"}, ExitUsage},
+		{"Unknown format", []string{"crawl", server.URL, "--format", "xml"}, ExitUsage},
+		{"Unknown flag", []string{"crawl", server.URL, "--fast"}, ExitUsage},
+		{"Missing input file", []string{"crawl", "--input", "does-not-exist.txt"}, ExitUsage},
+		{"Missing config file", []string{"crawl", server.URL, "--config", "does-not-exist.json"}, ExitUsage},
+		{"Invalid header", []string{"crawl", server.URL, "--header", "no colon"}, ExitUsage},
+		{"Unsupported proxy", []string{"crawl", server.URL, "--proxy", "ftp://proxy"}, ExitUsage},
+		{"Unknown analyzer", []string{"crawl", server.URL, "--disable-analyzers", "spelling"}, ExitUsage},
+		{"Not found", []string{"crawl", server.URL + "/missing"}, ExitFailure},
+		{"Invalid URL", []string{"crawl", "invalid-url"}, ExitFailure},
+		{"One of many fails", []string{"crawl", server.URL, server.URL + "/missing"}, ExitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := run("", tt.args...)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package crawler
+
+import (
+	"fmt"
+	"go-webcrawler/models"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// accessibilityRule is a check with the WCAG 2.1 success criteria it covers
+type accessibilityRule struct {
+	id       string
+	wcag     []string
+	severity models.Severity
+}
+
+var (
+	ruleImageAlt    = accessibilityRule{"image-alt", []string{"1.1.1"}, models.SeverityCritical}
+	ruleLabel       = accessibilityRule{"label", []string{"1.3.1", "4.1.2"}, models.SeverityCritical}
+	ruleButtonName  = accessibilityRule{"button-name", []string{"4.1.2"}, models.SeverityCritical}
+	ruleLinkName    = accessibilityRule{"link-name", []string{"2.4.4", "4.1.2"}, models.SeveritySerious}
+	ruleHTMLLang    = accessibilityRule{"html-lang", []string{"3.1.1"}, models.SeveritySerious}
+	ruleLinkGeneric = accessibilityRule{"link-generic", []string{"2.4.4"}, models.SeverityModerate}
+	ruleMain        = accessibilityRule{"landmark-main", []string{"1.3.1", "2.4.1"}, models.SeverityModerate}
+	ruleDuplicateID = accessibilityRule{"duplicate-id", []string{"4.1.1"}, models.SeverityMinor}
+)
+
+// genericLinkTexts say nothing about where a link goes when read on their own,
+// as screen reader users often do
+var genericLinkTexts = []string{
+	"click here", "click", "here", "read more", "more", "learn more", "more info", "details", "link", "this", "go",
+	"hier klicken", "hier", "mehr", "weiterlesen", "cliquez ici", "ici", "en savoir plus", "haga clic aquí", "aquí", "leer más",
+}
+
+// unlabeledInputs are input types that don't need a label
+var unlabeledInputs = []string{"hidden", "submit", "reset", "button", "image"}
+
+// AuditAccessibility checks the page against static accessibility rules.
+// Elements hidden with the hidden attribute or aria-hidden are skipped.
+func AuditAccessibility(n *html.Node) []models.AccessibilityFinding {
+	a := &audit{ids: make(map[string]int), labelled: make(map[string]bool)}
+	a.collect(n)
+	a.walk(n, false)
+
+	if !a.main {
+		a.add(ruleMain, nil, "No <main> element or role=\"main\", keyboard users can't skip to the content")
+	}
+	return a.findings
+}
+
+type audit struct {
+	findings []models.AccessibilityFinding
+	// ids counts the elements with each id
+	ids map[string]int
+	// labelled are the ids <label for> points at
+	labelled map[string]bool
+	main     bool
+}
+
+func (a *audit) add(rule accessibilityRule, n *html.Node, format string, args ...any) {
+	finding := models.AccessibilityFinding{
+		Rule:     rule.id,
+		WCAG:     rule.wcag,
+		Severity: rule.severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if n != nil {
+		finding.Path = domPath(n)
+	}
+	a.findings = append(a.findings, finding)
+}
+
+// collect counts ids and finds the labelled inputs before the walk, a label
+// may come after its input
+func (a *audit) collect(n *html.Node) {
+	if n.Type == html.ElementNode {
+		if id := getAttribute(n, "id"); id != "" {
+			a.ids[id]++
+		}
+		if n.Data == "label" {
+			if target := getAttribute(n, "for"); target != "" {
+				a.labelled[target] = true
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		a.collect(c)
+	}
+}
+
+// walk checks each element, inLabel is set inside a <label>
+func (a *audit) walk(n *html.Node, inLabel bool) {
+	if n.Type == html.ElementNode {
+		if hasAttribute(n, "hidden") || getAttribute(n, "aria-hidden") == "true" {
+			return
+		}
+		switch n.Data {
+		case "html":
+			if getAttribute(n, "lang") == "" {
+				a.add(ruleHTMLLang, n, "<html> has no lang attribute, screen readers may pick the wrong language")
+			}
+		case "main":
+			a.main = true
+		case "label":
+			inLabel = true
+		case "img":
+			a.checkImage(n)
+		case "a":
+			a.checkLink(n)
+		case "button":
+			if !hasName(n) {
+				a.add(ruleButtonName, n, "Button without text, aria-label or title")
+			}
+		case "input", "select", "textarea":
+			a.checkInput(n, inLabel)
+		}
+		if getAttribute(n, "role") == "main" {
+			a.main = true
+		}
+
+		id := getAttribute(n, "id")
+		if a.ids[id] > 1 {
+			a.add(ruleDuplicateID, n, "id %q is used by %d elements", id, a.ids[id])
+			// Report each id once
+			a.ids[id] = 0
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		a.walk(c, inLabel)
+	}
+}
+
+func (a *audit) checkImage(n *html.Node) {
+	if hasAttribute(n, "alt") || hasAriaLabel(n) {
+		return
+	}
+	if role := getAttribute(n, "role"); role == "presentation" || role == "none" {
+		return
+	}
+	a.add(ruleImageAlt, n, "Image %q has no alt attribute, use alt=\"\" for decorative images", getAttribute(n, "src"))
+}
+
+func (a *audit) checkLink(n *html.Node) {
+	if !hasAttribute(n, "href") {
+		return
+	}
+	if !hasName(n) {
+		a.add(ruleLinkName, n, "Link to %q has no text", getAttribute(n, "href"))
+		return
+	}
+	text := strings.ToLower(strings.Trim(anchorText(n), " .:!»›→"))
+	if slices.Contains(genericLinkTexts, text) {
+		a.add(ruleLinkGeneric, n, "Link text %q doesn't say where it goes", anchorText(n))
+	}
+}
+
+func (a *audit) checkInput(n *html.Node, inLabel bool) {
+	inputType := strings.ToLower(getAttribute(n, "type"))
+	if n.Data == "input" {
+		switch {
+		case inputType == "image":
+			if !hasAttribute(n, "alt") && !hasAriaLabel(n) {
+				a.add(ruleImageAlt, n, "Image button has no alt attribute")
+			}
+			return
+		case inputType == "button" && getAttribute(n, "value") == "" && !hasAriaLabel(n):
+			a.add(ruleButtonName, n, "Button input without a value or aria-label")
+			return
+		case slices.Contains(unlabeledInputs, inputType):
+			return
+		}
+	}
+
+	if inLabel || a.labelled[getAttribute(n, "id")] || hasAriaLabel(n) || getAttribute(n, "title") != "" {
+		return
+	}
+	name := getAttribute(n, "name")
+	if name == "" {
+		name = n.Data
+	}
+	a.add(ruleLabel, n, "Form field %q has no label", name)
+}
+
+// hasName reports whether a link or button has an accessible name
+func hasName(n *html.Node) bool {
+	return anchorText(n) != "" || hasAriaLabel(n)
+}
+
+func hasAriaLabel(n *html.Node) bool {
+	return getAttribute(n, "aria-label") != "" || getAttribute(n, "aria-labelledby") != ""
+}
+
+// accessibilitySummary counts findings per rule across pages
+type accessibilitySummary map[string]*models.AccessibilityRuleSummary
+
+func (s accessibilitySummary) add(findings []models.AccessibilityFinding) {
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		rule, ok := s[finding.Rule]
+		if !ok {
+			rule = &models.AccessibilityRuleSummary{Rule: finding.Rule, WCAG: finding.WCAG, Severity: finding.Severity}
+			s[finding.Rule] = rule
+		}
+		rule.Findings++
+		if !seen[finding.Rule] {
+			rule.Pages++
+			seen[finding.Rule] = true
+		}
+	}
+}
+
+// summary returns the rules by severity, then by number of findings
+func (s accessibilitySummary) summary() []models.AccessibilityRuleSummary {
+	var rules []models.AccessibilityRuleSummary
+	for _, rule := range s {
+		rules = append(rules, *rule)
+	}
+	slices.SortFunc(rules, func(a, b models.AccessibilityRuleSummary) int {
+		if a.Severity != b.Severity {
+			return severityRank(a.Severity) - severityRank(b.Severity)
+		}
+		if a.Findings != b.Findings {
+			return b.Findings - a.Findings
+		}
+		return strings.Compare(a.Rule, b.Rule)
+	})
+	return rules
+}
+
+func severityRank(severity models.Severity) int {
+	return slices.Index([]models.Severity{
+		models.SeverityCritical, models.SeveritySerious, models.SeverityModerate, models.SeverityMinor,
+	}, severity)
+}
@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestAuditAccessibility(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// findings are the expected rule ids in document order
+		findings []string
+	}{
+		{"Accessible", `<main><img src="a.png" alt="Chart"><img src="line.png" alt="">
+			<label>Email <input type="email" name="email"></label>
+			<label for="q">Search</label><input id="q" name="q"><input type="hidden" name="csrf">
+			<button><img src="x.png" alt="Close"></button><a href="/docs">Read the docs</a></main>`, nil},
+		{"Image without alt", `<main><img src="a.png"><img src="b.png" role="presentation"></main>`, []string{"image-alt"}},
+		{"Image button without alt", `<main><input type="image" src="go.png"></main>`, []string{"image-alt"}},
+		{"Unlabeled inputs", `<main><input name="q"><select name="size"></select><textarea aria-label="Note"></textarea>
+			<input name="phone" title="Phone"><input type="submit"></main>`, []string{"label", "label"}},
+		{"Empty link", `<main><a href="/"><svg></svg></a><a name="top"></a></main>`, []string{"link-name"}},
+		{"Generic links", `<main><a href="/a">Click here</a><a href="/b">Read more »</a><a href="/c">More about pricing</a></main>`, []string{"link-generic", "link-generic"}},
+		{"Empty buttons", `<main><button><i class="icon"></i></button><button aria-label="Menu"></button><input type="button"></main>`, []string{"button-name", "button-name"}},
+		{"Duplicate ids", `<main><div id="a"></div><div id="a"></div><div id="a"></div><div id="b"></div></main>`, []string{"duplicate-id"}},
+		{"Main role", `<div role="main"></div>`, nil},
+		{"Missing main", `<div></div>`, []string{"landmark-main"}},
+		{"Hidden elements", `<main><div hidden><img src="a.png"></div><a href="/" aria-hidden="true"></a></main>`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(`<html lang="en"><body>` + tt.body + `</body></html>`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var findings []string
+			for _, finding := range AuditAccessibility(doc) {
+				findings = append(findings, finding.Rule)
+			}
+			if !reflect.DeepEqual(findings, tt.findings) {
+				t.Errorf("Expected findings %v, got %v", tt.findings, findings)
+			}
+		})
+	}
+}
+
+func TestAuditAccessibility_Finding(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><body><main><p><img src="logo.png"></p></main></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.AccessibilityFinding{
+		{
+			Rule:     "html-lang",
+			WCAG:     []string{"3.1.1"},
+			Severity: models.SeveritySerious,
+			Message:  "<html> has no lang attribute, screen readers may pick the wrong language",
+			Path:     "html",
+		},
+		{
+			Rule:     "image-alt",
+			WCAG:     []string{"1.1.1"},
+			Severity: models.SeverityCritical,
+			Message:  `Image "logo.png" has no alt attribute, use alt="" for decorative images`,
+			Path:     "html > body > main > p > img",
+		},
+	}
+	if got := AuditAccessibility(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,181 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"go-webcrawler/models"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// Page is what an analyzer gets to look at
+type Page struct {
+	// URL is where the redirects ended
+	URL *url.URL
+	// Base is what relative URLs resolve against, the <base href> or URL
+	Base       *url.URL
+	Doc        *html.Node
+	StatusCode int
+	Header     http.Header
+	// Rendered is set when Doc is the DOM after scripts ran in Chrome
+	Rendered bool
+	// Canon canonicalizes URLs the same way the rest of the crawl does
+	Canon Canonicalizer
+	// Scope decides which links count as internal
+	Scope Scope
+
+	// session gives the built-in analyzers the crawl's link checker
+	session *session
+}
+
+// runs reports whether the analyzer called name runs on this page too
+func (p *Page) runs(name string) bool {
+	if p.session == nil {
+		return false
+	}
+	for _, a := range p.session.analyzers {
+		if a.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Analyzer looks at one aspect of a page. It fills in its part of result and
+// returns the problems it found. Analyzers run one after another in the order
+// they were registered, so they may read what earlier ones filled in.
+type Analyzer interface {
+	// Name identifies the analyzer in Options.Analyzers and Options.DisabledAnalyzers
+	Name() string
+	Analyze(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error)
+}
+
+// AnalyzerFunc is the signature of Analyzer.Analyze
+type AnalyzerFunc func(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error)
+
+// NewAnalyzer makes an Analyzer from a name and a function
+func NewAnalyzer(name string, analyze AnalyzerFunc) Analyzer {
+	return funcAnalyzer{name: name, analyze: analyze}
+}
+
+type funcAnalyzer struct {
+	name    string
+	analyze AnalyzerFunc
+}
+
+func (a funcAnalyzer) Name() string {
+	return a.name
+}
+
+func (a funcAnalyzer) Analyze(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	return a.analyze(ctx, page, result)
+}
+
+// Registry is an ordered set of analyzers with unique names
+type Registry struct {
+	mu        sync.RWMutex
+	analyzers []Analyzer
+}
+
+// NewRegistry returns a registry with the given analyzers. It panics on
+// duplicate names, like a second Register of the same name would fail.
+func NewRegistry(analyzers ...Analyzer) *Registry {
+	r := &Registry{}
+	for _, a := range analyzers {
+		if err := r.Register(a); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// DefaultRegistry has the built-in analyzers and is used by crawls that
+// don't set Options.Registry
+var DefaultRegistry = NewRegistry(builtinAnalyzers()...)
+
+// Register adds an analyzer to DefaultRegistry, after the built-ins
+func Register(a Analyzer) error {
+	return DefaultRegistry.Register(a)
+}
+
+// Register adds an analyzer that runs after the ones already registered
+func (r *Registry) Register(a Analyzer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a.Name() == "" {
+		return fmt.Errorf("analyzer without a name")
+	}
+	if slices.ContainsFunc(r.analyzers, func(registered Analyzer) bool { return registered.Name() == a.Name() }) {
+		return fmt.Errorf("analyzer %q is already registered", a.Name())
+	}
+	r.analyzers = append(r.analyzers, a)
+	return nil
+}
+
+// Names returns the names of the registered analyzers in the order they run
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.analyzers))
+	for i, a := range r.analyzers {
+		names[i] = a.Name()
+	}
+	return names
+}
+
+// selected returns the analyzers to run. enabled limits them to those names
+// when it isn't empty, disabled leaves names out.
+func (r *Registry) selected(enabled, disabled []string) ([]Analyzer, error) {
+	names := r.Names()
+	for _, name := range append(slices.Clone(enabled), disabled...) {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("unknown analyzer %q, registered are %v", name, names)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var analyzers []Analyzer
+	for _, a := range r.analyzers {
+		if (len(enabled) == 0 || slices.Contains(enabled, a.Name())) && !slices.Contains(disabled, a.Name()) {
+			analyzers = append(analyzers, a)
+		}
+	}
+	return analyzers, nil
+}
+
+// runAnalyzers runs the analyzers on page. A failing or panicking analyzer is
+// recorded in AnalyzerErrors and doesn't stop the others.
+func runAnalyzers(ctx context.Context, analyzers []Analyzer, page *Page, result *models.CrawlResult) {
+	for _, a := range analyzers {
+		findings, err := runAnalyzer(ctx, a, page, result)
+		if err != nil {
+			if result.AnalyzerErrors == nil {
+				result.AnalyzerErrors = make(map[string]string)
+			}
+			result.AnalyzerErrors[a.Name()] = err.Error()
+		}
+		for _, finding := range findings {
+			if finding.Analyzer == "" {
+				finding.Analyzer = a.Name()
+			}
+			result.Findings = append(result.Findings, finding)
+		}
+	}
+}
+
+func runAnalyzer(ctx context.Context, a Analyzer, page *Page, result *models.CrawlResult) (findings []models.Finding, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return a.Analyze(ctx, page, result)
+}
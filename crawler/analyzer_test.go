@@ -0,0 +1,177 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-webcrawler/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	noop := func(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+		return nil, nil
+	}
+	r := NewRegistry(NewAnalyzer("a", noop))
+
+	if err := r.Register(NewAnalyzer("b", noop)); err != nil {
+		t.Errorf("Expected b to register, got %v", err)
+	}
+	if err := r.Register(NewAnalyzer("a", noop)); err == nil {
+		t.Error("Expected a duplicate name to fail")
+	}
+	if err := r.Register(NewAnalyzer("", noop)); err == nil {
+		t.Error("Expected an empty name to fail")
+	}
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", names)
+	}
+}
+
+func TestRegistry_Selected(t *testing.T) {
+	noop := func(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+		return nil, nil
+	}
+	r := NewRegistry(NewAnalyzer("a", noop), NewAnalyzer("b", noop), NewAnalyzer("c", noop))
+
+	tests := []struct {
+		name     string
+		enabled  []string
+		disabled []string
+		want     []string
+		wantErr  bool
+	}{
+		{"All", nil, nil, []string{"a", "b", "c"}, false},
+		{"Enabled keep their order", []string{"c", "a"}, nil, []string{"a", "c"}, false},
+		{"Disabled", nil, []string{"b"}, []string{"a", "c"}, false},
+		{"Both", []string{"a", "b"}, []string{"b"}, []string{"a"}, false},
+		{"Unknown enabled", []string{"d"}, nil, nil, true},
+		{"Unknown disabled", nil, []string{"d"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzers, err := r.selected(tt.enabled, tt.disabled)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			var names []string
+			for _, a := range analyzers {
+				names = append(names, a.Name())
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, names)
+			}
+		})
+	}
+}
+
+func TestCrawlURL_Analyzers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Shop</title></head><body><img src="a.png"><a href="/cart">Cart</a></body></html>`)
+	}))
+	defer server.Close()
+
+	// A team's own check, reading the response headers and what the title analyzer filled in
+	headers := NewAnalyzer("headers", func(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+		if page.Header.Get("Content-Security-Policy") == "" {
+			return []models.Finding{{Code: "missing_csp", Severity: models.SeverityModerate, Message: "No CSP on " + result.Title}}, nil
+		}
+		return nil, nil
+	})
+	failing := NewAnalyzer("failing", func(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+		return nil, errors.New("out of coffee")
+	})
+	panicking := NewAnalyzer("panicking", func(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+		panic("boom")
+	})
+	registry := NewRegistry(append(builtinAnalyzers(), headers, failing, panicking)...)
+
+	t.Run("Custom", func(t *testing.T) {
+		result := CrawlURLWithOptions(context.Background(), server.URL, Options{Registry: registry, HostRequestsPerSecond: -1})
+
+		if !result.Success || result.Title != "Shop" || result.InternalLinks != 1 {
+			t.Errorf("Expected the built-ins to run, got success %v, title %q and %d internal links", result.Success, result.Title, result.InternalLinks)
+		}
+		if result.Resources[models.ResourceImage] != 1 {
+			t.Errorf("Expected the image to be counted as a resource, got %v", result.Resources)
+		}
+		want := models.Finding{Analyzer: "headers", Code: "missing_csp", Severity: models.SeverityModerate, Message: "No CSP on Shop"}
+		if !containsFinding(result.Findings, want) {
+			t.Errorf("Expected %+v in %+v", want, result.Findings)
+		}
+		if !containsFinding(result.Findings, models.Finding{
+			Analyzer: AnalyzerAccessibility, Code: "image-alt", Severity: models.SeverityCritical,
+			Message: `Image "a.png" has no alt attribute, use alt="" for decorative images`, Path: "html > body > img",
+		}) {
+			t.Errorf("Expected the built-in accessibility finding in %+v", result.Findings)
+		}
+		if countFindings(result.Findings, "html-lang") != 1 || countFindings(result.Findings, "lang_missing") != 0 {
+			t.Errorf("Expected the missing lang only once, as html-lang, in %+v", result.Findings)
+		}
+		if result.AnalyzerErrors["failing"] != "out of coffee" || !strings.Contains(result.AnalyzerErrors["panicking"], "boom") {
+			t.Errorf("Expected the failing analyzers to be recorded, got %v", result.AnalyzerErrors)
+		}
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		result := CrawlURLWithOptions(context.Background(), server.URL, Options{
+			Registry:  registry,
+			Analyzers: []string{AnalyzerTitle, "headers"},
+		})
+
+		if result.Title != "Shop" || len(result.Findings) != 1 {
+			t.Errorf("Expected the title and the headers finding, got %q and %+v", result.Title, result.Findings)
+		}
+		if result.Links != nil || result.Accessibility != nil || result.Outline.Headings != nil {
+			t.Error("Expected the other analyzers not to run")
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		result := CrawlURLWithOptions(context.Background(), server.URL, Options{
+			DisabledAnalyzers: []string{AnalyzerLinks, AnalyzerAccessibility},
+		})
+
+		if result.Title != "Shop" {
+			t.Errorf("Expected the title analyzer to run, got %q", result.Title)
+		}
+		if result.Links != nil || result.Accessibility != nil {
+			t.Errorf("Expected links and accessibility to be skipped, got %d links and %d findings", len(result.Links), len(result.Accessibility))
+		}
+		if countFindings(result.Findings, "lang_missing") != 1 {
+			t.Errorf("Expected metadata to report the missing lang without accessibility, got %+v", result.Findings)
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		result := CrawlURLWithOptions(context.Background(), server.URL, Options{Analyzers: []string{"spelling"}})
+
+		if result.Success || !strings.Contains(result.Error, `unknown analyzer "spelling"`) {
+			t.Errorf("Expected an unknown analyzer to fail the crawl, got %q", result.Error)
+		}
+	})
+}
+
+func countFindings(findings []models.Finding, code string) int {
+	n := 0
+	for _, finding := range findings {
+		if finding.Code == code {
+			n++
+		}
+	}
+	return n
+}
+
+func containsFinding(findings []models.Finding, want models.Finding) bool {
+	for _, finding := range findings {
+		if finding == want {
+			return true
+		}
+	}
+	return false
+}
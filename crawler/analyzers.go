@@ -0,0 +1,192 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"go-webcrawler/models"
+)
+
+// Names of the built-in analyzers
+const (
+	AnalyzerTitle          = "title"
+	AnalyzerHTMLVersion    = "html_version"
+	AnalyzerHeadings       = "headings"
+	AnalyzerLoginForms     = "login_forms"
+	AnalyzerForms          = "forms"
+	AnalyzerMetadata       = "metadata"
+	AnalyzerStructuredData = "structured_data"
+	AnalyzerAccessibility  = "accessibility"
+	AnalyzerLinks          = "links"
+)
+
+// builtinAnalyzers are in the order they run. Links come last, checking them
+// takes the longest.
+func builtinAnalyzers() []Analyzer {
+	return []Analyzer{
+		NewAnalyzer(AnalyzerTitle, analyzeTitle),
+		NewAnalyzer(AnalyzerHTMLVersion, analyzeHTMLVersion),
+		NewAnalyzer(AnalyzerHeadings, analyzeHeadings),
+		NewAnalyzer(AnalyzerLoginForms, analyzeLoginForms),
+		NewAnalyzer(AnalyzerForms, analyzeForms),
+		NewAnalyzer(AnalyzerMetadata, analyzeMetadata),
+		NewAnalyzer(AnalyzerStructuredData, analyzeStructuredData),
+		NewAnalyzer(AnalyzerAccessibility, analyzeAccessibility),
+		NewAnalyzer(AnalyzerLinks, analyzeLinks),
+	}
+}
+
+func analyzeTitle(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	title := ExtractTitle(page.Doc)
+	if title == "" {
+		result.Title = "No title found"
+	} else {
+		result.Title = title
+	}
+	return nil, nil
+}
+
+func analyzeHTMLVersion(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	result.HTMLVersion, result.DocType = ExtractHTMLVersion(page.Doc)
+	return nil, nil
+}
+
+func analyzeHeadings(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	result.Headings = ExtractHeadings(page.Doc)
+	result.Outline = ExtractOutline(page.Doc)
+
+	var findings []models.Finding
+	for _, issue := range result.Outline.Issues {
+		severity := models.SeverityMinor
+		if issue.Code == "missing_h1" || issue.Code == "multiple_h1" {
+			severity = models.SeverityModerate
+		}
+		findings = append(findings, models.Finding{Code: issue.Code, Severity: severity, Message: issue.Message, Path: issue.Path})
+	}
+	return findings, nil
+}
+
+func analyzeLoginForms(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	result.AuthForms = DetectAuthForms(page.Doc)
+	result.HasLoginForm = hasLoginForm(result.AuthForms)
+	return nil, nil
+}
+
+func analyzeForms(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	result.Forms = extractForms(page.Doc, page.URL, page.Base, page.Canon)
+
+	var findings []models.Finding
+	for _, form := range result.Forms {
+		if form.InsecurePassword {
+			findings = append(findings, models.Finding{
+				Code:     "insecure_password",
+				Severity: models.SeveritySerious,
+				Message:  fmt.Sprintf("Password sent over http to %s", form.Action),
+				Path:     form.Path,
+			})
+		}
+		if form.CrossOrigin {
+			findings = append(findings, models.Finding{
+				Code:     "cross_origin",
+				Severity: models.SeverityMinor,
+				Message:  fmt.Sprintf("Form submits to %s", form.Action),
+				Path:     form.Path,
+			})
+		}
+	}
+	return findings, nil
+}
+
+func analyzeMetadata(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	result.Metadata = extractMetadata(page.Doc, page.Base, page.Canon, page.Header.Get("Content-Type"))
+
+	var findings []models.Finding
+	for _, issue := range result.Metadata.Issues {
+		// The html-lang accessibility rule reports the same problem
+		if issue.Field == "lang" && page.runs(AnalyzerAccessibility) {
+			continue
+		}
+		severity := models.SeverityModerate
+		switch {
+		case issue.Code == "too_short" || issue.Code == "too_long":
+			severity = models.SeverityMinor
+		case issue.Field == "title" || issue.Field == "robots":
+			severity = models.SeveritySerious
+		}
+		findings = append(findings, models.Finding{Code: issue.Field + "_" + issue.Code, Severity: severity, Message: issue.Message})
+	}
+	return findings, nil
+}
+
+func analyzeStructuredData(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	result.StructuredData = ExtractStructuredData(page.Doc)
+
+	var findings []models.Finding
+	for _, err := range result.StructuredData.Errors {
+		findings = append(findings, models.Finding{
+			Code:     string(err.Format) + "_error",
+			Severity: models.SeverityModerate,
+			Message:  err.Message,
+			Path:     err.Path,
+		})
+	}
+	return findings, nil
+}
+
+func analyzeAccessibility(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	result.Accessibility = AuditAccessibility(page.Doc)
+
+	var findings []models.Finding
+	for _, finding := range result.Accessibility {
+		findings = append(findings, models.Finding{Code: finding.Rule, Severity: finding.Severity, Message: finding.Message, Path: finding.Path})
+	}
+	return findings, nil
+}
+
+// analyzeLinks resolves every link against the page and probes them to find
+// the broken ones
+func analyzeLinks(ctx context.Context, page *Page, result *models.CrawlResult) ([]models.Finding, error) {
+	links := resolveLinks(page.Doc, page.Base, page.URL, page.Canon, page.Scope)
+	result.InternalLinks, result.ExternalLinks, result.OtherLinks = countLinks(links)
+	result.Resources = countResources(links)
+	result.MixedContent = markMixedContent(links, page.URL)
+
+	if page.session != nil {
+		links = withStatuses(links, page.session.links.check(ctx, uniqueURLs(links)))
+	}
+	result.Links = links
+	result.InaccessibleLinks = countInaccessible(result.Links)
+
+	var findings []models.Finding
+	reported := make(map[string]bool)
+	for _, link := range result.Links {
+		if link.Broken() && !reported[link.URL] {
+			reported[link.URL] = true
+			findings = append(findings, models.Finding{
+				Code:     "broken_link",
+				Severity: models.SeveritySerious,
+				Message:  fmt.Sprintf("%s is not accessible: %s", link.URL, linkProblem(link)),
+				Path:     link.Path,
+			})
+		}
+		if link.MixedContent {
+			findings = append(findings, models.Finding{
+				Code:     "mixed_content",
+				Severity: models.SeveritySerious,
+				Message:  fmt.Sprintf("%s is loaded over http", link.URL),
+				Path:     link.Path,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// linkProblem describes why a link check failed
+func linkProblem(link models.Link) string {
+	if link.Error != "" {
+		return link.Error
+	}
+	if link.StatusCode != 0 {
+		return fmt.Sprintf("status %d", link.StatusCode)
+	}
+	return string(link.Status)
+}
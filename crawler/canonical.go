@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/idna"
+)
+
+// trackingParams are removed on top of every utm_* parameter when tracking is stripped
+var trackingParams = map[string]bool{
+	"gclid":   true,
+	"dclid":   true,
+	"fbclid":  true,
+	"msclkid": true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+}
+
+// Canonicalizer turns URLs into the single form used to count, compare and dedupe them
+type Canonicalizer struct {
+	// StripTracking removes utm_* and click ID parameters
+	StripTracking bool
+}
+
+// Parse parses an absolute URL and canonicalizes it
+func (c Canonicalizer) Parse(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	return c.Canonical(u), nil
+}
+
+// Resolve resolves href against base like a browser would and canonicalizes the result
+func (c Canonicalizer) Resolve(base *url.URL, href string) (*url.URL, error) {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return nil, err
+	}
+	return c.Canonical(base.ResolveReference(ref)), nil
+}
+
+// Canonical returns a copy of u with a lowercase scheme, an ASCII lowercase host
+// without the default port, dot segments resolved and no fragment
+func (c Canonicalizer) Canonical(u *url.URL) *url.URL {
+	canonical := *u
+	canonical.Scheme = strings.ToLower(canonical.Scheme)
+	canonical.Fragment = ""
+	canonical.RawFragment = ""
+
+	if canonical.Host != "" {
+		canonical.Host = canonicalHost(canonical.Scheme, canonical.Host)
+	}
+
+	if canonical.Scheme == "http" || canonical.Scheme == "https" {
+		if canonical.Path == "" {
+			canonical.Path = "/"
+			canonical.RawPath = ""
+		} else if strings.Contains(canonical.Path, "/.") {
+			// Resolving the path against itself removes . and .. segments
+			resolved := canonical.ResolveReference(&url.URL{Path: canonical.Path, RawPath: canonical.RawPath})
+			canonical.Path, canonical.RawPath = resolved.Path, resolved.RawPath
+		}
+	}
+
+	if c.StripTracking && canonical.RawQuery != "" {
+		canonical.RawQuery = stripTrackingParams(canonical.RawQuery)
+	}
+	if canonical.RawQuery == "" {
+		canonical.ForceQuery = false
+	}
+
+	return &canonical
+}
+
+func canonicalHost(scheme, host string) string {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname, port = host, ""
+	}
+	hostname = strings.TrimSuffix(strings.Trim(hostname, "[]"), ".")
+
+	// IDN hosts are compared in their punycode form, IP addresses are left alone
+	if net.ParseIP(hostname) == nil {
+		if ascii, err := idna.Lookup.ToASCII(hostname); err == nil {
+			hostname = ascii
+		}
+	}
+	hostname = strings.ToLower(hostname)
+
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+
+	if strings.Contains(hostname, ":") {
+		hostname = "[" + hostname + "]"
+	}
+	if port != "" {
+		return hostname + ":" + port
+	}
+	return hostname
+}
+
+// stripTrackingParams drops tracking parameters and keeps the rest in their original order
+func stripTrackingParams(rawQuery string) string {
+	var kept []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "utm_") || trackingParams[key] {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	return strings.Join(kept, "&")
+}
+
+// documentBase returns the URL relative links in doc resolve against, which is
+// the first <base href> when there is one and pageURL otherwise
+func documentBase(doc *html.Node, pageURL *url.URL) *url.URL {
+	var href string
+	var find func(n *html.Node) bool
+	find = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Base {
+			for _, attr := range n.Attr {
+				if attr.Key == "href" {
+					href = strings.TrimSpace(attr.Val)
+					return true
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if find(c) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !find(doc) || href == "" {
+		return pageURL
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return pageURL
+	}
+	base := pageURL.ResolveReference(ref)
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return pageURL
+	}
+	return base
+}
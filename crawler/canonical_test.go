@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCanonicalizer(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		stripTracking bool
+		expected      string
+	}{
+		{"Lowercase scheme and host", "HTTPS://WWW.Doruk.COM/Path", false, "https://www.doruk.com/Path"},
+		{"Empty path", "https://doruk.com", false, "https://doruk.com/"},
+		{"Default HTTPS port", "https://doruk.com:443/a", false, "https://doruk.com/a"},
+		{"Default HTTP port", "http://doruk.com:80/a", false, "http://doruk.com/a"},
+		{"Other port kept", "https://doruk.com:8443/a", false, "https://doruk.com:8443/a"},
+		{"Fragment removed", "https://doruk.com/a#section", false, "https://doruk.com/a"},
+		{"Dot segments", "https://doruk.com/a/./b/../c", false, "https://doruk.com/a/c"},
+		{"Trailing dot in host", "https://doruk.com./a", false, "https://doruk.com/a"},
+		{"IDN host", "https://Bücher.example/a", false, "https://xn--bcher-kva.example/a"},
+		{"Punycode host kept", "https://xn--bcher-kva.example/a", false, "https://xn--bcher-kva.example/a"},
+		{"IPv6 host", "http://[::1]:80/a", false, "http://[::1]/a"},
+		{"Empty query dropped", "https://doruk.com/a?", false, "https://doruk.com/a"},
+		{"Tracking kept by default", "https://doruk.com/a?utm_source=x&id=1", false, "https://doruk.com/a?utm_source=x&id=1"},
+		{"Tracking stripped", "https://doruk.com/a?utm_source=x&id=1&UTM_Medium=y&fbclid=z&b=2", true, "https://doruk.com/a?id=1&b=2"},
+		{"Only tracking", "https://doruk.com/a?utm_campaign=x&gclid=y", true, "https://doruk.com/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := Canonicalizer{StripTracking: tt.stripTracking}.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if got := u.String(); got != tt.expected {
+				t.Errorf("Canonical(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCanonicalizerResolve(t *testing.T) {
+	base, _ := url.Parse("https://doruk.com/docs/guide/index.html")
+
+	tests := []struct {
+		href     string
+		expected string
+	}{
+		{"../a", "https://doruk.com/docs/a"},
+		{"../../../../a", "https://doruk.com/a"},
+		{"./b#top", "https://doruk.com/docs/guide/b"},
+		{"?page=2", "https://doruk.com/docs/guide/index.html?page=2"},
+		{"//CDN.doruk.com/x.js", "https://cdn.doruk.com/x.js"},
+		{"https://other.com", "https://other.com/"},
+	}
+
+	for _, tt := range tests {
+		resolved, err := Canonicalizer{}.Resolve(base, tt.href)
+		if err != nil {
+			t.Fatalf("Resolve(%q) failed: %v", tt.href, err)
+		}
+		if got := resolved.String(); got != tt.expected {
+			t.Errorf("Resolve(%q) = %q; want %q", tt.href, got, tt.expected)
+		}
+	}
+}
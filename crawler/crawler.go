@@ -1,9 +1,12 @@
 package crawler
 
 import (
+	"context"
 	"fmt"
 	"go-webcrawler/models"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"golang.org/x/net/html"
@@ -14,72 +17,177 @@ const (
 )
 
 func CrawlURL(url string) models.CrawlResult {
-	normalizedURL := NormalizeURL(url)
+	return CrawlURLWithOptions(context.Background(), url, Options{})
+}
 
-	result := models.CrawlResult{
-		URL: normalizedURL,
-	}
+func CrawlURLWithOptions(ctx context.Context, url string, opts Options) models.CrawlResult {
+	return newSession(opts).crawlPage(ctx, url)
+}
+
+// session holds the state shared by all pages fetched in one crawl
+type session struct {
+	opts   Options
+	client *http.Client
+	links  *linkChecker
+	robots *robotsCache
+	// limiter is shared by page fetches and link checks
+	limiter *HostLimiter
+	canon   Canonicalizer
+	// render is nil unless pages are rendered in Chrome
+	render *renderer
+	// analyzers run on every page, in order
+	analyzers []Analyzer
+	// err is set when opts could not be turned into an HTTP client
+	err error
+}
 
-	client := &http.Client{
-		Timeout: RequestTimeout,
+func newSession(opts Options) *session {
+	opts = opts.withDefaults()
+	s := &session{
+		opts:    opts,
+		limiter: newHostLimiter(opts).withParent(opts.Limiter),
+		canon:   Canonicalizer{StripTracking: opts.StripTrackingParams},
 	}
 
-	req, err := http.NewRequest("GET", normalizedURL, nil)
+	transport, err := opts.newTransport()
 	if err != nil {
-		result.Error = fmt.Sprintf("Failed to create request: %v", err)
-		result.Success = false
-		return result
+		s.err = err
+		return s
+	}
+	s.analyzers, err = opts.registry().selected(opts.Analyzers, opts.DisabledAnalyzers)
+	if err != nil {
+		s.err = err
+		return s
+	}
+
+	s.client = &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		// fetch follows redirects itself to record the chain
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	s.robots = newRobotsCache(&http.Client{Transport: transport, Timeout: opts.Timeout})
+	s.robots.limiter = s.limiter
+	s.links = newLinkChecker(newLinkClient(transport, opts.LinkTimeout))
+	s.links.limiter = s.limiter
+	if !opts.IgnoreRobots {
+		s.links.robots = s.robots
+		s.links.userAgent = opts.robotsAgent()
+	}
+	if opts.Render {
+		s.render = newRenderer(opts)
 	}
+	return s
+}
 
-	// Add headers to mimic a real browser
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-	req.Header.Set("Connection", "keep-alive")
+// renderPage loads the page in Chrome. It takes a turn of the host limiter
+// for the page itself, the assets Chrome loads are not limited.
+func (s *session) renderPage(ctx context.Context, pageURL string) (*html.Node, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	release, err := s.limiter.acquire(ctx, u.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	resp, err := client.Do(req)
+	rendered, err := s.render.render(ctx, pageURL)
 	if err != nil {
-		result.Error = fmt.Sprintf("Network error: %v", err)
+		return nil, err
+	}
+	return html.Parse(strings.NewReader(rendered))
+}
+
+func (s *session) crawlPage(ctx context.Context, rawURL string) models.CrawlResult {
+	result, _ := s.crawl(ctx, rawURL)
+	return result
+}
+
+// crawl fetches and analyzes a page. It also returns the pages the page links
+// to, whether the links analyzer runs or not, for a site crawl to follow.
+func (s *session) crawl(ctx context.Context, rawURL string) (models.CrawlResult, []models.Link) {
+	normalizedURL := NormalizeURL(rawURL)
+
+	result := models.CrawlResult{
+		URL:       normalizedURL,
+		CrawledAt: time.Now(),
+	}
+
+	if s.err != nil {
+		result.Error = fmt.Sprintf("Invalid crawler options: %v", s.err)
 		result.Success = false
-		return result
+		return result, nil
+	}
+
+	// The User-Agent and other headers come from the session transport
+	resp := s.fetch(ctx, &result)
+	if resp == nil {
+		result.Success = false
+		return result, nil
 	}
 	defer resp.Body.Close()
 
-	result.StatusCode = resp.StatusCode
-	result.Status = resp.Status
+	success := s.opts.SuccessStatuses.Contains(resp.StatusCode)
+	if !success {
+		result.Error = DescribeResponse(resp.StatusCode, resp.Header)
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		result.Error = GetStatusCodeDescription(resp.StatusCode)
-		result.Success = false
-		return result
+	analyze := s.opts.ParseStatuses.Contains(resp.StatusCode) || (!success && s.opts.AnalyzeErrorPages)
+	if !analyze {
+		result.Success = success
+		return result, nil
 	}
 
 	doc, err := html.Parse(resp.Body)
+	// Free the host's connection slot before the links, which may be on the same host, are checked
+	resp.Body.Close()
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to parse HTML: %v", err)
 		result.Success = false
-		return result
+		return result, nil
 	}
 
-	// Extract title
-	title := ExtractTitle(doc)
-	if title == "" {
-		result.Title = "No title found"
-	} else {
-		result.Title = title
+	// Analyze the DOM after scripts ran, or the static HTML when rendering fails
+	if s.render != nil {
+		if rendered, err := s.renderPage(ctx, result.FinalURL); err != nil {
+			result.RenderError = err.Error()
+		} else {
+			doc = rendered
+			result.Rendered = true
+		}
 	}
 
-	// Extract HTML version and DOCTYPE
-	result.HTMLVersion, result.DocType = ExtractHTMLVersion(doc)
-
-	// Extract headings
-	result.Headings = ExtractHeadings(doc)
-
-	// Detect login form
-	result.HasLoginForm = DetectLoginForm(doc)
+	// Analyzers see the page relative to where the redirects ended
+	finalURL, _ := url.Parse(result.FinalURL)
+	page := &Page{
+		URL:        finalURL,
+		Base:       documentBase(doc, finalURL),
+		Doc:        doc,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Rendered:   result.Rendered,
+		Canon:      s.canon,
+		Scope:      s.opts.LinkScope,
+		session:    s,
+	}
+	runAnalyzers(ctx, s.analyzers, page, &result)
 
-	// Extract link information
-	result.InternalLinks, result.ExternalLinks, result.InaccessibleLinks = ExtractLinks(doc, normalizedURL)
+	result.Analyzed = true
+	result.Success = success
+	return result, pageLinks(resolveLinks(page.Doc, page.Base, page.URL, s.canon, s.opts.LinkScope))
+}
 
-	result.Success = true
-	return result
+// pageLinks returns the http(s) links that lead to another page
+func pageLinks(links []models.Link) []models.Link {
+	var pages []models.Link
+	for _, link := range links {
+		if link.Web() && link.Navigation() {
+			pages = append(pages, link)
+		}
+	}
+	return pages
 }
@@ -20,9 +20,12 @@ func TestCrawlURL_InvalidURL(t *testing.T) {
 }
 
 func TestCrawlURL_ValidResponse(t *testing.T) {
+	external, externalURL := newExternalServer()
+	defer external.Close()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
-		fmt.Fprint(w, `
+		fmt.Fprintf(w, `
 			<!DOCTYPE html>
 			<html>
 				<head>
@@ -32,14 +35,14 @@ func TestCrawlURL_ValidResponse(t *testing.T) {
 					<h1>Main Heading</h1>
 					<h2>Sub Heading</h2>
 					<a href="/internal">Internal Link</a>
-					<a href="https://external.com">External Link</a>
+					<a href="%s">External Link</a>
 					<form id="login-form">
 						<input type="text" name="username">
 						<input type="password" name="password">
 					</form>
 				</body>
 			</html>
-		`)
+		`, externalURL)
 	}))
 	defer server.Close()
 
@@ -121,3 +124,36 @@ func TestCrawlURL_NoTitle(t *testing.T) {
 		t.Errorf("Expected 'No title found', got %q", result.Title)
 	}
 }
+
+func TestCrawlURL_BrokenLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body>
+				<a href="/ok">OK</a>
+				<a href="/missing">Missing</a>
+				<a href="/broken">Broken</a>
+				<a href="#top">Fragment</a>
+			</body></html>`)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	result := CrawlURL(server.URL + "/")
+
+	if !result.Success {
+		t.Fatalf("Expected successful crawl, got error: %s", result.Error)
+	}
+
+	if len(result.Links) != 3 {
+		t.Errorf("Expected 3 checked links, got %d", len(result.Links))
+	}
+
+	if result.InaccessibleLinks != 2 {
+		t.Errorf("Expected 2 inaccessible links, got %d", result.InaccessibleLinks)
+	}
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// getAttribute returns the trimmed value of the key attribute, or "" when it is missing
+func getAttribute(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+// textContent returns the text below n with whitespace collapsed
+func textContent(n *html.Node) string {
+	var b strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		case html.ElementNode:
+			if n.Data == "script" || n.Data == "style" {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// domPath returns a CSS selector like "html > body > ul > li:nth-of-type(2) > a"
+// that finds n again. Elements with siblings of the same tag get an :nth-of-type().
+func domPath(n *html.Node) string {
+	var parts []string
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		index, count := 0, 0
+		if n.Parent != nil {
+			for c := n.Parent.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && c.Data == n.Data {
+					count++
+					if c == n {
+						index = count
+					}
+				}
+			}
+		}
+
+		part := n.Data
+		if count > 1 {
+			part = fmt.Sprintf("%s:nth-of-type(%d)", n.Data, index)
+		}
+		parts = append(parts, part)
+	}
+
+	slices.Reverse(parts)
+	return strings.Join(parts, " > ")
+}
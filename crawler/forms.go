@@ -0,0 +1,147 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// csrfNames are parts of the names frameworks give their CSRF token field,
+// like Rails' authenticity_token, Django's csrfmiddlewaretoken, Laravel's
+// _token and ASP.NET's __RequestVerificationToken
+var csrfNames = []string{"csrf", "xsrf", "authenticity", "requestverification", "_token", "nonce"}
+
+// ExtractForms lists every form on the page at pageURL
+func ExtractForms(n *html.Node, pageURL string) []models.Form {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	return extractForms(n, page, documentBase(n, page), Canonicalizer{})
+}
+
+// extractForms resolves form actions against base. Forms without an action
+// submit to page itself, whatever the <base href> says.
+func extractForms(n *html.Node, page, base *url.URL, canon Canonicalizer) []models.Form {
+	var forms []models.Form
+
+	var walk func(n *html.Node, form *models.Form)
+	walk = func(n *html.Node, form *models.Form) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "form":
+				if form == nil {
+					forms = append(forms, newForm(n, page, base, canon))
+					form = &forms[len(forms)-1]
+				}
+			case "input", "select", "textarea", "button":
+				if form != nil {
+					addFormInput(form, n)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, form)
+		}
+	}
+	walk(n, nil)
+
+	// Actions are canonical, so the page has to be too for its origin to match
+	origin := canon.Canonical(page)
+	for i := range forms {
+		checkForm(&forms[i], origin)
+	}
+	return forms
+}
+
+func newForm(n *html.Node, page, base *url.URL, canon Canonicalizer) models.Form {
+	form := models.Form{
+		Method:  strings.ToUpper(getAttribute(n, "method")),
+		Enctype: strings.ToLower(getAttribute(n, "enctype")),
+		Inputs:  []models.FormInput{},
+		Path:    domPath(n),
+	}
+	if form.Method != "POST" && form.Method != "DIALOG" {
+		form.Method = "GET"
+	}
+	if form.Enctype == "" {
+		form.Enctype = "application/x-www-form-urlencoded"
+	}
+
+	action := getAttribute(n, "action")
+	if action == "" {
+		form.Action = canon.Canonical(page).String()
+	} else if resolved, err := canon.Resolve(base, action); err == nil {
+		form.Action = resolved.String()
+	} else {
+		form.Action = action
+	}
+	return form
+}
+
+func addFormInput(form *models.Form, n *html.Node) {
+	input := models.FormInput{
+		Name:     getAttribute(n, "name"),
+		Type:     n.Data,
+		Required: hasAttribute(n, "required"),
+	}
+
+	switch n.Data {
+	case "input":
+		input.Type = strings.ToLower(getAttribute(n, "type"))
+		if input.Type == "" {
+			input.Type = "text"
+		}
+	case "button":
+		// Only named buttons send a value
+		if input.Name == "" {
+			return
+		}
+	}
+
+	if input.Type == "hidden" && form.CSRFField == "" && isCSRFName(input.Name) {
+		form.CSRFField = input.Name
+	}
+	form.Inputs = append(form.Inputs, input)
+}
+
+// checkForm flags forms that submit to another origin or send a password over
+// http. page has to be canonical like the action.
+func checkForm(form *models.Form, page *url.URL) {
+	action, err := url.Parse(form.Action)
+	if err != nil || (action.Scheme != "http" && action.Scheme != "https") {
+		return
+	}
+
+	form.CrossOrigin = action.Scheme != page.Scheme || action.Host != page.Host
+
+	for _, input := range form.Inputs {
+		if input.Type == "password" && action.Scheme == "http" {
+			form.InsecurePassword = true
+		}
+	}
+}
+
+func isCSRFName(name string) bool {
+	name = strings.ToLower(name)
+	if name == "token" {
+		return true
+	}
+	for _, part := range csrfNames {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAttribute(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractForms(t *testing.T) {
+	tests := []struct {
+		name    string
+		pageURL string
+		htmlStr string
+		want    models.Form
+	}{
+		{
+			"Defaults",
+			"https://example.com/search?q=go",
+			`<form><input name="q"></form>`,
+			models.Form{
+				Method:  "GET",
+				Action:  "https://example.com/search?q=go",
+				Enctype: "application/x-www-form-urlencoded",
+				Inputs:  []models.FormInput{{Name: "q", Type: "text"}},
+				Path:    "html > body > form",
+			},
+		},
+		{
+			"Login with CSRF token",
+			"https://example.com/login",
+			`<form method="post" action="/session">
+				<input type="hidden" name="authenticity_token" value="abc">
+				<input type="email" name="email" required>
+				<input type="password" name="password" required>
+				<button>Sign in</button></form>`,
+			models.Form{
+				Method:  "POST",
+				Action:  "https://example.com/session",
+				Enctype: "application/x-www-form-urlencoded",
+				Inputs: []models.FormInput{
+					{Name: "authenticity_token", Type: "hidden"},
+					{Name: "email", Type: "email", Required: true},
+					{Name: "password", Type: "password", Required: true},
+				},
+				CSRFField: "authenticity_token",
+				Path:      "html > body > form",
+			},
+		},
+		{
+			"Upload to another origin",
+			"https://example.com/",
+			`<form method="POST" action="https://uploads.example.net/files" enctype="Multipart/Form-Data">
+				<input type="file" name="file"><textarea name="note"></textarea>
+				<select name="folder"><option>a</option></select>
+				<button name="action" value="save">Save</button></form>`,
+			models.Form{
+				Method:  "POST",
+				Action:  "https://uploads.example.net/files",
+				Enctype: "multipart/form-data",
+				Inputs: []models.FormInput{
+					{Name: "file", Type: "file"},
+					{Name: "note", Type: "textarea"},
+					{Name: "folder", Type: "select"},
+					{Name: "action", Type: "button"},
+				},
+				CrossOrigin: true,
+				Path:        "html > body > form",
+			},
+		},
+		{
+			"Same origin written differently",
+			"https://Example.com:443/",
+			`<form method="post" action="https://example.com/login"><input type="password" name="pw"></form>`,
+			models.Form{
+				Method:  "POST",
+				Action:  "https://example.com/login",
+				Enctype: "application/x-www-form-urlencoded",
+				Inputs:  []models.FormInput{{Name: "pw", Type: "password"}},
+				Path:    "html > body > form",
+			},
+		},
+		{
+			"Password over http",
+			"https://example.com/",
+			`<form method="post" action="http://example.com/login"><input type="password" name="pw"></form>`,
+			models.Form{
+				Method:           "POST",
+				Action:           "http://example.com/login",
+				Enctype:          "application/x-www-form-urlencoded",
+				Inputs:           []models.FormInput{{Name: "pw", Type: "password"}},
+				CrossOrigin:      true,
+				InsecurePassword: true,
+				Path:             "html > body > form",
+			},
+		},
+		{
+			"Password on an http page",
+			"http://example.com/login",
+			`<form method="post"><input type="password" name="pw"></form>`,
+			models.Form{
+				Method:           "POST",
+				Action:           "http://example.com/login",
+				Enctype:          "application/x-www-form-urlencoded",
+				Inputs:           []models.FormInput{{Name: "pw", Type: "password"}},
+				InsecurePassword: true,
+				Path:             "html > body > form",
+			},
+		},
+		{
+			"Action relative to base",
+			"https://example.com/blog/post",
+			`<html><head><base href="https://example.com/app/"></head><body>
+				<form action="subscribe" method="dialog"><button>Close</button></form></body></html>`,
+			models.Form{
+				Method:  "DIALOG",
+				Action:  "https://example.com/app/subscribe",
+				Enctype: "application/x-www-form-urlencoded",
+				Inputs:  []models.FormInput{},
+				Path:    "html > body > form",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.htmlStr))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			forms := ExtractForms(doc, tt.pageURL)
+			if len(forms) != 1 {
+				t.Fatalf("Expected 1 form, got %d", len(forms))
+			}
+			if !reflect.DeepEqual(forms[0], tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, forms[0])
+			}
+		})
+	}
+}
+
+func TestIsCSRFName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"csrfmiddlewaretoken", true},
+		{"_csrf", true},
+		{"__RequestVerificationToken", true},
+		{"_token", true},
+		{"XSRF-TOKEN", true},
+		{"token", true},
+		{"session_id", false},
+		{"redirect_to", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isCSRFName(tt.name); got != tt.want {
+			t.Errorf("isCSRFName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package crawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"go-webcrawler/models"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	MaxLinkCheckers  = 10
+	LinkCheckTimeout = 5 * time.Second
+)
+
+func CheckLinks(ctx context.Context, urls []string) []models.Link {
+	// The default options always produce a transport
+	transport, _ := DefaultOptions().newTransport()
+	return newLinkChecker(newLinkClient(transport, LinkCheckTimeout)).check(ctx, urls)
+}
+
+// linkChecker remembers results so links shared between pages are probed once
+type linkChecker struct {
+	client  *http.Client
+	workers int
+	limiter *HostLimiter
+	// robots is nil when robots.txt is ignored
+	robots *robotsCache
+	// userAgent picks the robots.txt group, see Options.robotsAgent
+	userAgent string
+
+	mu      sync.Mutex
+	checked map[string]models.Link
+}
+
+func newLinkChecker(client *http.Client) *linkChecker {
+	return &linkChecker{
+		client:    client,
+		workers:   MaxLinkCheckers,
+		userAgent: RobotsUserAgent,
+		checked:   make(map[string]models.Link),
+	}
+}
+
+func (lc *linkChecker) check(ctx context.Context, urls []string) []models.Link {
+	var pending []string
+	lc.mu.Lock()
+	for _, url := range urls {
+		if _, ok := lc.checked[url]; !ok {
+			pending = append(pending, url)
+		}
+	}
+	lc.mu.Unlock()
+
+	probed := lc.probe(ctx, pending)
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	for _, link := range probed {
+		// Links skipped because of cancellation are not worth remembering
+		if link.Status != "" {
+			lc.checked[link.URL] = link
+		}
+	}
+
+	links := make([]models.Link, len(urls))
+	for i, url := range urls {
+		links[i] = lc.checked[url]
+	}
+	return links
+}
+
+// probe checks urls with up to lc.workers requests at once
+func (lc *linkChecker) probe(ctx context.Context, urls []string) []models.Link {
+	links := make([]models.Link, len(urls))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < lc.workers && i < len(urls); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				links[idx] = lc.checkPolitely(ctx, urls[idx])
+			}
+		}()
+	}
+
+	for idx := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	return links
+}
+
+// checkPolitely asks robots.txt and waits for the limiter before probing. A link
+// that never got its turn because of cancellation is returned without a status.
+func (lc *linkChecker) checkPolitely(ctx context.Context, rawURL string) models.Link {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return CheckLink(ctx, lc.client, rawURL)
+	}
+
+	if lc.robots != nil {
+		robots := lc.robots.get(ctx, u)
+		if !robots.Allowed(lc.userAgent, robotsPath(u)) {
+			return models.Link{URL: rawURL, Status: models.LinkBlocked, Error: "Blocked by robots.txt"}
+		}
+		lc.limiter.setCrawlDelay(u.Host, robots.CrawlDelay(lc.userAgent))
+	}
+
+	release, err := lc.limiter.acquire(ctx, u.Host)
+	if err != nil {
+		return models.Link{URL: rawURL}
+	}
+	defer release()
+
+	return CheckLink(ctx, lc.client, rawURL)
+}
+
+func CheckLink(ctx context.Context, client *http.Client, url string) models.Link {
+	link := models.Link{URL: url}
+
+	statusCode, err := probeLink(ctx, client, http.MethodHead, url)
+	// Some servers reject or mishandle HEAD, so retry those with a GET
+	if needsGetFallback(statusCode, err) {
+		statusCode, err = probeLink(ctx, client, http.MethodGet, url)
+	}
+
+	if err != nil {
+		link.Status = classifyLinkError(err)
+		link.Error = err.Error()
+		return link
+	}
+
+	link.StatusCode = statusCode
+	link.Status = classifyLinkStatus(statusCode)
+	return link
+}
+
+func probeLink(ctx context.Context, client *http.Client, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+func needsGetFallback(statusCode int, err error) bool {
+	if err != nil {
+		status := classifyLinkError(err)
+		return status != models.LinkTimeout && status != models.LinkDNSError
+	}
+	return statusCode >= 400
+}
+
+// newLinkClient uses http.DefaultTransport when transport is nil
+func newLinkClient(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		// Report redirects as they are instead of following them
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func classifyLinkStatus(statusCode int) models.LinkStatus {
+	switch {
+	case statusCode >= 500:
+		return models.LinkServerError
+	case statusCode >= 400:
+		return models.LinkClientError
+	case statusCode >= 300:
+		return models.LinkRedirect
+	default:
+		return models.LinkOK
+	}
+}
+
+func classifyLinkError(err error) models.LinkStatus {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return models.LinkDNSError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return models.LinkTimeout
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var unknownAuthErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return models.LinkTLSError
+	}
+
+	return models.LinkNetworkError
+}
+
+// withStatuses copies the result of each check to every link with that URL
+func withStatuses(links, checked []models.Link) []models.Link {
+	byURL := make(map[string]models.Link, len(checked))
+	for _, link := range checked {
+		byURL[link.URL] = link
+	}
+
+	for i, link := range links {
+		if result, ok := byURL[link.URL]; ok && link.Checked() {
+			links[i].StatusCode = result.StatusCode
+			links[i].Status = result.Status
+			links[i].Error = result.Error
+		}
+	}
+	return links
+}
+
+// countInaccessible counts the distinct URLs of broken links
+func countInaccessible(links []models.Link) int {
+	broken := make(map[string]bool)
+	for _, link := range links {
+		if link.Broken() {
+			broken[link.URL] = true
+		}
+	}
+	return len(broken)
+}
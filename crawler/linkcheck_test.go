@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"context"
+	"go-webcrawler/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer tlsServer.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		expected   models.LinkStatus
+	}{
+		{"OK", server.URL + "/ok", 200, models.LinkOK},
+		{"Redirect", server.URL + "/redirect", 301, models.LinkRedirect},
+		{"Not found", server.URL + "/missing", 404, models.LinkClientError},
+		{"Server error", server.URL + "/broken", 500, models.LinkServerError},
+		{"HEAD not allowed", server.URL + "/no-head", 200, models.LinkOK},
+		{"Timeout", server.URL + "/slow", 0, models.LinkTimeout},
+		{"DNS failure", "http://does-not-exist.invalid", 0, models.LinkDNSError},
+		{"TLS error", tlsServer.URL, 0, models.LinkTLSError},
+	}
+
+	client := newLinkClient(nil, 100*time.Millisecond)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CheckLink(context.Background(), client, tt.url)
+			if result.Status != tt.expected {
+				t.Errorf("CheckLink(%q).Status = %q; want %q (error: %s)", tt.url, result.Status, tt.expected, result.Error)
+			}
+			if result.StatusCode != tt.statusCode {
+				t.Errorf("CheckLink(%q).StatusCode = %d; want %d", tt.url, result.StatusCode, tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	urls := []string{
+		server.URL + "/a",
+		server.URL + "/missing",
+		server.URL + "/b",
+	}
+
+	checker := newLinkChecker(newLinkClient(nil, time.Second))
+	checker.workers = 2
+	links := checker.probe(context.Background(), urls)
+
+	if len(links) != len(urls) {
+		t.Fatalf("Expected %d links, got %d", len(urls), len(links))
+	}
+
+	for i, link := range links {
+		if link.URL != urls[i] {
+			t.Errorf("Expected link %d to be %q, got %q", i, urls[i], link.URL)
+		}
+	}
+
+	if inaccessible := countInaccessible(links); inaccessible != 1 {
+		t.Errorf("Expected 1 inaccessible link, got %d", inaccessible)
+	}
+}
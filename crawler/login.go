@@ -0,0 +1,355 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// LoginThreshold is the confidence from which a login form sets HasLoginForm
+const LoginThreshold = 0.5
+
+// reportThreshold is the confidence a form needs to be listed at all, so a
+// newsletter box with an email field isn't reported as a password reset
+const reportThreshold = 0.3
+
+// Button texts in English, German, French, Spanish, Italian, Portuguese,
+// Dutch, Polish, Turkish, Russian, Japanese, Chinese and Korean
+var (
+	loginPhrases = []string{
+		"log in", "login", "log on", "logon", "sign in", "signin",
+		"anmelden", "einloggen", "connexion", "se connecter", "iniciar sesión", "ingresar",
+		"entrar", "accedi", "inloggen", "zaloguj", "giriş", "oturum aç", "войти",
+		"ログイン", "登录", "登入", "로그인",
+	}
+	signupPhrases = []string{
+		"sign up", "signup", "register", "create account", "create an account", "join",
+		"registrieren", "konto erstellen", "s'inscrire", "inscription", "créer un compte",
+		"registrarse", "crear cuenta", "registrati", "cadastrar", "criar conta", "registreren",
+		"zarejestruj", "kayıt ol", "üye ol", "hesap oluştur", "регистрация", "зарегистрироваться",
+		"新規登録", "注册", "註冊", "가입",
+	}
+	resetPhrases = []string{
+		"reset", "forgot", "recover", "passwort vergessen", "zurücksetzen", "mot de passe oublié",
+		"réinitialiser", "olvidaste", "restablecer", "recuperar", "wachtwoord vergeten",
+		"şifremi unuttum", "sıfırla", "восстановить", "сбросить", "パスワードを忘れ", "忘记密码", "重置",
+	}
+	// ssoPhrases introduce a provider, like "Continue with Google"
+	ssoPhrases = []string{
+		"sign in with", "log in with", "login with", "continue with", "sign up with",
+		"anmelden mit", "se connecter avec", "continuer avec", "iniciar sesión con", "continuar con",
+		"ile giriş", "ile devam et", "войти через",
+	}
+	// ssoHosts are identity providers that links and buttons point at
+	ssoHosts = []string{
+		"accounts.google.com", "login.microsoftonline.com", "login.live.com", "appleid.apple.com",
+		"github.com/login/oauth", "facebook.com/dialog/oauth", "www.facebook.com/v", "gitlab.com/oauth",
+		"okta.com", "auth0.com", "onelogin.com",
+	}
+)
+
+// Tokens of form names, ids, classes and action paths. Adjacent tokens are
+// joined too, so "sign-in" matches "signin".
+var (
+	loginTokens    = []string{"login", "signin", "logon", "session", "sessions", "auth", "authenticate", "authentication", "sso"}
+	signupTokens   = []string{"signup", "register", "registration", "join", "createaccount", "newaccount"}
+	resetTokens    = []string{"reset", "forgot", "forgotpassword", "resetpassword", "recover", "recovery", "lostpassword"}
+	usernameTokens = []string{"user", "username", "userid", "email", "mail", "login", "account", "phone"}
+	signupFields   = []string{"firstname", "lastname", "fullname", "birthday", "birthdate", "dob", "terms", "agree", "tos", "confirm", "confirmation"}
+)
+
+// formFields is what the classification looks at in a form, or in the inputs
+// and buttons outside any form
+type formFields struct {
+	node *html.Node
+	// names are the tokens of the form's id, class and name
+	names        []string
+	action       []string
+	passwords    int
+	usernames    int
+	autocomplete map[string]bool
+	resetToken   bool
+	rememberMe   bool
+	extraFields  []string
+	buttons      []string
+	forgotLink   bool
+	sso          []string
+}
+
+func DetectLoginForm(n *html.Node) bool {
+	return hasLoginForm(DetectAuthForms(n))
+}
+
+func hasLoginForm(forms []models.AuthForm) bool {
+	for _, form := range forms {
+		if form.Kind == models.FormLogin && form.Confidence >= LoginThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectAuthForms classifies each form on the page as a login, signup or
+// password reset form. Inputs and buttons outside any form are looked at as
+// one more form, single-page apps often leave the <form> out.
+func DetectAuthForms(n *html.Node) []models.AuthForm {
+	page := &formFields{autocomplete: make(map[string]bool)}
+	var forms []*formFields
+
+	var walk func(n *html.Node, form *formFields)
+	walk = func(n *html.Node, form *formFields) {
+		if n.Type == html.ElementNode {
+			if n.Data == "form" && form == page {
+				form = newFormFields(n)
+				forms = append(forms, form)
+			} else {
+				form.add(n)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, form)
+		}
+	}
+	walk(n, page)
+
+	var detected []models.AuthForm
+	for _, form := range append(forms, page) {
+		if auth, ok := form.classify(); ok {
+			detected = append(detected, auth)
+		}
+	}
+	return detected
+}
+
+func newFormFields(n *html.Node) *formFields {
+	form := &formFields{node: n, autocomplete: make(map[string]bool)}
+	for _, key := range []string{"id", "class", "name"} {
+		form.names = append(form.names, nameTokens(getAttribute(n, key))...)
+	}
+	if action := getAttribute(n, "action"); action != "" {
+		if u, err := url.Parse(action); err == nil {
+			form.action = nameTokens(u.Path)
+		}
+	}
+	return form
+}
+
+// add records the inputs, buttons and links inside the form
+func (f *formFields) add(n *html.Node) {
+	switch n.Data {
+	case "input":
+		f.addInput(n)
+	case "button":
+		if text := textContent(n); text != "" {
+			f.buttons = append(f.buttons, text)
+		}
+		f.addSSO(n, getAttribute(n, "formaction"))
+	case "a":
+		text := strings.ToLower(textContent(n))
+		if containsAny(text, resetPhrases) {
+			f.forgotLink = true
+		}
+		f.addSSO(n, getAttribute(n, "href"))
+	}
+}
+
+func (f *formFields) addInput(n *html.Node) {
+	inputType := strings.ToLower(getAttribute(n, "type"))
+	names := append(nameTokens(getAttribute(n, "name")), nameTokens(getAttribute(n, "id"))...)
+	for _, token := range strings.Fields(strings.ToLower(getAttribute(n, "autocomplete"))) {
+		f.autocomplete[token] = true
+	}
+
+	switch inputType {
+	case "password":
+		f.passwords++
+	case "submit", "button", "image":
+		if value := getAttribute(n, "value"); value != "" {
+			f.buttons = append(f.buttons, value)
+		}
+		f.addSSO(n, getAttribute(n, "formaction"))
+	case "hidden":
+		if hasToken(names, []string{"reset", "resettoken", "resetpassword"}) {
+			f.resetToken = true
+		}
+	case "checkbox":
+		if hasToken(names, []string{"remember", "rememberme", "keep"}) {
+			f.rememberMe = true
+		}
+		if field, ok := firstToken(names, signupFields); ok {
+			f.extraFields = append(f.extraFields, field)
+		}
+	case "", "text", "email", "tel":
+		if inputType == "email" || hasToken(names, usernameTokens) {
+			f.usernames++
+		}
+		if field, ok := firstToken(names, signupFields); ok {
+			f.extraFields = append(f.extraFields, field)
+		}
+	}
+}
+
+// addSSO records buttons and links for single sign-on providers
+func (f *formFields) addSSO(n *html.Node, href string) {
+	lowerHref := strings.ToLower(href)
+	for _, host := range ssoHosts {
+		if strings.Contains(lowerHref, host) {
+			f.sso = append(f.sso, host)
+			return
+		}
+	}
+	if u, err := url.Parse(lowerHref); err == nil && hasToken(nameTokens(u.Path), []string{"oauth", "oauth2", "saml", "openid", "sso"}) {
+		f.sso = append(f.sso, u.Path)
+		return
+	}
+
+	text := strings.ToLower(anchorText(n))
+	for _, phrase := range ssoPhrases {
+		if strings.Contains(text, phrase) {
+			f.sso = append(f.sso, text)
+			return
+		}
+	}
+}
+
+// classify scores the form for each kind. The highest score wins, ties go
+// to the more specific kind: password reset, then signup, then login.
+func (f *formFields) classify() (models.AuthForm, bool) {
+	login := &score{}
+	signup := &score{}
+	reset := &score{}
+
+	if f.passwords == 1 {
+		login.add(0.4, "password_input")
+	}
+	if f.passwords > 0 {
+		signup.add(0.1, "password_input")
+	}
+	if f.passwords > 1 {
+		signup.add(0.3, "confirm_password")
+	}
+	if f.autocomplete["current-password"] {
+		login.add(0.3, "autocomplete=current-password")
+	}
+	if f.autocomplete["new-password"] {
+		signup.add(0.3, "autocomplete=new-password")
+	}
+	if f.autocomplete["username"] {
+		login.add(0.1, "autocomplete=username")
+	} else if f.usernames > 0 && f.passwords > 0 {
+		login.add(0.1, "username_input")
+	}
+	if f.rememberMe {
+		login.add(0.1, "remember_me")
+	}
+	if f.forgotLink && f.passwords > 0 {
+		login.add(0.1, "forgot_password_link")
+	}
+	if len(f.sso) > 0 {
+		login.add(0.4, "sso:"+f.sso[0])
+	}
+	if f.resetToken {
+		reset.add(0.2, "reset_token")
+	}
+	for _, field := range f.extraFields {
+		signup.add(0.2, "field:"+field)
+	}
+
+	for _, button := range f.buttons {
+		text := strings.ToLower(button)
+		switch {
+		case containsAny(text, resetPhrases):
+			reset.add(0.5, "button:"+button)
+		case containsAny(text, signupPhrases):
+			signup.add(0.4, "button:"+button)
+		case containsAny(text, loginPhrases):
+			login.add(0.3, "button:"+button)
+		}
+	}
+
+	scoreTokens("name:", f.names, login, signup, reset)
+	scoreTokens("action:", f.action, login, signup, reset)
+
+	kind, best := models.FormPasswordReset, reset
+	if signup.value > best.value {
+		kind, best = models.FormSignup, signup
+	}
+	if login.value > best.value {
+		kind, best = models.FormLogin, login
+	}
+	if best.value < reportThreshold {
+		return models.AuthForm{}, false
+	}
+
+	auth := models.AuthForm{
+		Kind:       kind,
+		Confidence: math.Round(min(best.value, 1)*100) / 100,
+		Signals:    best.signals,
+	}
+	if f.node != nil {
+		auth.Path = domPath(f.node)
+	}
+	return auth, true
+}
+
+type score struct {
+	value   float64
+	signals []string
+}
+
+func (s *score) add(weight float64, signal string) {
+	s.value += weight
+	s.signals = append(s.signals, signal)
+}
+
+// scoreTokens adds the strongest kind the tokens of a name or action path point at
+func scoreTokens(source string, tokens []string, login, signup, reset *score) {
+	if token, ok := firstToken(tokens, resetTokens); ok {
+		reset.add(0.3, source+token)
+	} else if token, ok := firstToken(tokens, signupTokens); ok {
+		signup.add(0.3, source+token)
+	} else if token, ok := firstToken(tokens, loginTokens); ok {
+		login.add(0.2, source+token)
+	}
+}
+
+// nameTokens splits an id, class or path into lowercase words and adds each
+// pair of adjacent words joined, so "sign-in" and "sign_in" give "signin"
+func nameTokens(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := slices.Clone(words)
+	for i := 1; i < len(words); i++ {
+		tokens = append(tokens, words[i-1]+words[i])
+	}
+	return tokens
+}
+
+func hasToken(tokens, words []string) bool {
+	_, ok := firstToken(tokens, words)
+	return ok
+}
+
+func firstToken(tokens, words []string) (string, bool) {
+	for _, token := range tokens {
+		if slices.Contains(words, token) {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+func containsAny(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
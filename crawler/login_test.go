@@ -0,0 +1,126 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestDetectAuthForms(t *testing.T) {
+	tests := []struct {
+		name    string
+		htmlStr string
+		kind    models.FormKind
+		// minimum and maximum confidence
+		low, high float64
+		signal    string
+	}{
+		{
+			"Login with autocomplete",
+			`<form action="/session"><input name="email" autocomplete="username">
+				<input type="password" autocomplete="current-password"><button>Sign in</button></form>`,
+			models.FormLogin, 0.9, 1, "autocomplete=current-password",
+		},
+		{
+			"German login button",
+			`<form><input type="text" name="benutzer"><input type="password"><input type="submit" value="Anmelden"></form>`,
+			models.FormLogin, 0.6, 0.8, "button:Anmelden",
+		},
+		{
+			"Japanese login button",
+			`<form><input type="password"><button>ログイン</button></form>`,
+			models.FormLogin, 0.6, 0.8, "button:ログイン",
+		},
+		{
+			"Login action only",
+			`<form action="/users/sign_in"><input type="password"></form>`,
+			models.FormLogin, 0.5, 0.7, "action:signin",
+		},
+		{
+			"Remember me and forgot link",
+			`<form><input type="password"><input type="checkbox" name="remember_me">
+				<a href="/forgot">Forgot your password?</a></form>`,
+			models.FormLogin, 0.5, 0.7, "remember_me",
+		},
+		{
+			"SSO buttons without a form",
+			`<div><a href="https://accounts.google.com/o/oauth2/auth?client_id=1">Continue with Google</a></div>`,
+			models.FormLogin, 0.4, 0.4, "sso:accounts.google.com",
+		},
+		{
+			"Password input outside a form",
+			`<div id="app"><input type="email"><input type="password"><button>Log in</button></div>`,
+			models.FormLogin, 0.8, 0.8, "button:Log in",
+		},
+		{
+			"Signup",
+			`<form action="/register"><input name="first_name"><input type="email">
+				<input type="password" autocomplete="new-password"><input type="password" name="password_confirmation">
+				<input type="checkbox" name="terms"><button>Create account</button></form>`,
+			models.FormSignup, 1, 1, "confirm_password",
+		},
+		{
+			"Password reset request",
+			`<form action="/password/forgot"><input type="email" name="email"><button>Send reset link</button></form>`,
+			models.FormPasswordReset, 0.8, 0.8, "button:Send reset link",
+		},
+		{
+			"Password reset with new password",
+			`<form action="/reset"><input type="hidden" name="reset_password_token" value="abc">
+				<input type="password" autocomplete="new-password"><input type="password">
+				<button>Reset password</button></form>`,
+			models.FormPasswordReset, 1, 1, "reset_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader("<html><body>" + tt.htmlStr + "</body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			forms := DetectAuthForms(doc)
+			if len(forms) != 1 {
+				t.Fatalf("Expected 1 form, got %+v", forms)
+			}
+			form := forms[0]
+			if form.Kind != tt.kind {
+				t.Errorf("Expected kind %q, got %q with signals %v", tt.kind, form.Kind, form.Signals)
+			}
+			if form.Confidence < tt.low || form.Confidence > tt.high {
+				t.Errorf("Expected confidence between %.2f and %.2f, got %.2f with signals %v", tt.low, tt.high, form.Confidence, form.Signals)
+			}
+			if !slices.Contains(form.Signals, tt.signal) {
+				t.Errorf("Expected signal %q, got %v", tt.signal, form.Signals)
+			}
+		})
+	}
+}
+
+func TestDetectAuthForms_Ignored(t *testing.T) {
+	tests := []struct {
+		name    string
+		htmlStr string
+	}{
+		{"Newsletter", `<form action="/newsletter"><input type="email" name="email"><button>Subscribe</button></form>`},
+		{"Search", `<form action="/search"><input type="text" name="q"><button>Search</button></form>`},
+		{"Author filter", `<form class="author-filter"><input type="text" name="author"></form>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader("<html><body>" + tt.htmlStr + "</body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			if forms := DetectAuthForms(doc); len(forms) != 0 {
+				t.Errorf("Expected no authentication forms, got %+v", forms)
+			}
+		})
+	}
+}
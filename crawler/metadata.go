@@ -0,0 +1,251 @@
+package crawler
+
+import (
+	"fmt"
+	"go-webcrawler/models"
+	"mime"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Lengths in characters that search results show without cutting off, and
+// below which titles and descriptions rarely describe the page
+const (
+	titleMinLength       = 30
+	titleMaxLength       = 60
+	descriptionMinLength = 70
+	descriptionMaxLength = 160
+)
+
+// openGraphRequired are the properties every Open Graph object needs
+var openGraphRequired = []string{"og:title", "og:type", "og:image", "og:url"}
+
+// twitterCards are the valid values of twitter:card
+var twitterCards = []string{"summary", "summary_large_image", "app", "player"}
+
+// iconRels are the rel values of favicons and home screen icons
+var iconRels = []string{"icon", "shortcut icon", "apple-touch-icon", "apple-touch-icon-precomposed", "mask-icon"}
+
+// ExtractMetadata reads the SEO and social metadata of the page at pageURL and validates it
+func ExtractMetadata(n *html.Node, pageURL string) models.Metadata {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return models.Metadata{}
+	}
+	return extractMetadata(n, documentBase(n, page), Canonicalizer{}, "")
+}
+
+// extractMetadata resolves URLs against base. contentType is the response's
+// Content-Type header, which may declare the charset instead of a meta tag.
+func extractMetadata(n *html.Node, base *url.URL, canon Canonicalizer, contentType string) models.Metadata {
+	m := metadataFields{}
+	m.walk(n, base, canon)
+
+	meta := m.Metadata
+	if meta.Charset == "" {
+		if _, params, err := mime.ParseMediaType(contentType); err == nil {
+			meta.Charset = strings.ToLower(params["charset"])
+		}
+	}
+	meta.Issues = m.check(ExtractTitle(n), meta)
+	return meta
+}
+
+// metadataFields collects the metadata and how often each tag was seen
+type metadataFields struct {
+	models.Metadata
+	descriptions int
+	canonicals   int
+}
+
+func (m *metadataFields) walk(n *html.Node, base *url.URL, canon Canonicalizer) {
+	if n.Type == html.ElementNode {
+		switch n.Data {
+		case "html":
+			m.Lang = getAttribute(n, "lang")
+		case "meta":
+			m.addMeta(n)
+		case "link":
+			m.addLink(n, base, canon)
+		case "svg", "math":
+			// Their <title> and <link> elements aren't the page's
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		m.walk(c, base, canon)
+	}
+}
+
+func (m *metadataFields) addMeta(n *html.Node) {
+	content := getAttribute(n, "content")
+	if charset := getAttribute(n, "charset"); charset != "" {
+		m.Charset = strings.ToLower(charset)
+		return
+	}
+	if strings.EqualFold(getAttribute(n, "http-equiv"), "content-type") {
+		if _, params, err := mime.ParseMediaType(content); err == nil && m.Charset == "" {
+			m.Charset = strings.ToLower(params["charset"])
+		}
+		return
+	}
+
+	// Open Graph uses property, but name is common enough to accept
+	name := strings.ToLower(getAttribute(n, "property"))
+	if name == "" {
+		name = strings.ToLower(getAttribute(n, "name"))
+	}
+
+	switch {
+	case name == "description":
+		m.descriptions++
+		if m.Description == "" {
+			m.Description = content
+		}
+	case name == "robots":
+		for _, directive := range strings.Split(strings.ToLower(content), ",") {
+			if directive = strings.TrimSpace(directive); directive != "" && !slices.Contains(m.Robots, directive) {
+				m.Robots = append(m.Robots, directive)
+			}
+		}
+	case name == "viewport":
+		m.Viewport = content
+	case strings.HasPrefix(name, "og:"):
+		m.OpenGraph = setFirst(m.OpenGraph, name, content)
+	case strings.HasPrefix(name, "twitter:"):
+		m.Twitter = setFirst(m.Twitter, name, content)
+	}
+}
+
+func (m *metadataFields) addLink(n *html.Node, base *url.URL, canon Canonicalizer) {
+	rel := strings.Join(strings.Fields(strings.ToLower(getAttribute(n, "rel"))), " ")
+	href := getAttribute(n, "href")
+	if href == "" {
+		return
+	}
+	resolved := href
+	if u, err := canon.Resolve(base, href); err == nil {
+		resolved = u.String()
+	}
+
+	switch {
+	case rel == "canonical":
+		m.canonicals++
+		if m.Canonical == "" {
+			m.Canonical = resolved
+		}
+	case rel == "alternate" && getAttribute(n, "hreflang") != "":
+		m.Alternates = append(m.Alternates, models.Alternate{Hreflang: strings.ToLower(getAttribute(n, "hreflang")), URL: resolved})
+	case slices.Contains(iconRels, rel):
+		m.Icons = append(m.Icons, models.Icon{Rel: rel, URL: resolved, Sizes: getAttribute(n, "sizes")})
+	}
+}
+
+// check validates the title and the collected metadata
+func (m *metadataFields) check(title string, meta models.Metadata) []models.MetadataIssue {
+	var issues []models.MetadataIssue
+	add := func(field, code, format string, args ...any) {
+		issues = append(issues, models.MetadataIssue{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	issues = append(issues, checkLength("title", title, titleMinLength, titleMaxLength)...)
+	issues = append(issues, checkLength("description", meta.Description, descriptionMinLength, descriptionMaxLength)...)
+	if m.descriptions > 1 {
+		add("description", "multiple", "%d description meta tags, search engines may pick any of them", m.descriptions)
+	}
+
+	switch {
+	case m.canonicals == 0:
+		add("canonical", "missing", "No canonical link")
+	case m.canonicals > 1:
+		add("canonical", "multiple", "%d canonical links, search engines ignore all of them", m.canonicals)
+	}
+
+	for _, conflict := range robotsConflicts(meta.Robots) {
+		add("robots", "conflict", "Conflicting robots directives %s", conflict)
+	}
+
+	seen := make(map[string]bool)
+	for _, alternate := range meta.Alternates {
+		if seen[alternate.Hreflang] {
+			add("hreflang", "duplicate", "More than one alternate for %q", alternate.Hreflang)
+		}
+		seen[alternate.Hreflang] = true
+	}
+
+	if len(meta.OpenGraph) > 0 {
+		var missing []string
+		for _, property := range openGraphRequired {
+			if meta.OpenGraph[property] == "" {
+				missing = append(missing, property)
+			}
+		}
+		if len(missing) > 0 {
+			add("open_graph", "incomplete", "Missing %s", strings.Join(missing, ", "))
+		}
+	}
+	if len(meta.Twitter) > 0 {
+		if card := meta.Twitter["twitter:card"]; card == "" {
+			add("twitter", "missing_card", "twitter:card is missing")
+		} else if !slices.Contains(twitterCards, card) {
+			add("twitter", "invalid_card", "Unknown twitter:card %q", card)
+		}
+	}
+
+	if meta.Viewport == "" {
+		add("viewport", "missing", "No viewport meta tag, mobile browsers will zoom out")
+	}
+	if meta.Charset == "" {
+		add("charset", "missing", "No charset in a meta tag or the Content-Type header")
+	}
+	if meta.Lang == "" {
+		add("lang", "missing", "No lang attribute on <html>")
+	}
+	return issues
+}
+
+func checkLength(field, value string, low, high int) []models.MetadataIssue {
+	length := utf8.RuneCountInString(value)
+	switch {
+	case length == 0:
+		return []models.MetadataIssue{{Field: field, Code: "missing", Message: "No " + field}}
+	case length < low:
+		return []models.MetadataIssue{{Field: field, Code: "too_short", Message: fmt.Sprintf("%d characters, at least %d recommended", length, low)}}
+	case length > high:
+		return []models.MetadataIssue{{Field: field, Code: "too_long", Message: fmt.Sprintf("%d characters, search results cut off after %d", length, high)}}
+	}
+	return nil
+}
+
+// robotsConflicts returns the pairs of directives that contradict each other
+func robotsConflicts(directives []string) []string {
+	var conflicts []string
+	for _, pair := range [][2]string{
+		{"index", "noindex"},
+		{"follow", "nofollow"},
+		{"all", "noindex"},
+		{"all", "nofollow"},
+		{"all", "none"},
+		{"none", "index"},
+		{"none", "follow"},
+	} {
+		if slices.Contains(directives, pair[0]) && slices.Contains(directives, pair[1]) {
+			conflicts = append(conflicts, pair[0]+" and "+pair[1])
+		}
+	}
+	return conflicts
+}
+
+func setFirst(values map[string]string, key, value string) map[string]string {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	if _, ok := values[key]; !ok {
+		values[key] = value
+	}
+	return values
+}
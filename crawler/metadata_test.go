@@ -0,0 +1,137 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	htmlStr := `<!DOCTYPE html><html lang="en"><head>
+		<meta charset="UTF-8">
+		<title>Go web crawler that checks links, forms and SEO</title>
+		<meta name="description" content="Crawl a site, list every link with its status and audit the metadata search engines and social networks read.">
+		<meta name="robots" content="index, follow">
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<link rel="canonical" href="/docs/">
+		<link rel="alternate" hreflang="de" href="https://example.com/de/docs/">
+		<link rel="alternate" hreflang="x-default" href="https://example.com/docs/">
+		<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+		<meta property="og:title" content="Crawler">
+		<meta property="og:type" content="website">
+		<meta property="og:image" content="https://example.com/og.png">
+		<meta property="og:url" content="https://example.com/docs/">
+		<meta name="twitter:card" content="summary_large_image">
+		<link rel="icon" href="/favicon.ico">
+		<link rel="apple-touch-icon" sizes="180x180" href="/apple-touch-icon.png">
+		</head><body><svg><link rel="canonical" href="/svg"></svg></body></html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := models.Metadata{
+		Description: "Crawl a site, list every link with its status and audit the metadata search engines and social networks read.",
+		Robots:      []string{"index", "follow"},
+		Canonical:   "https://example.com/docs/",
+		Alternates: []models.Alternate{
+			{Hreflang: "de", URL: "https://example.com/de/docs/"},
+			{Hreflang: "x-default", URL: "https://example.com/docs/"},
+		},
+		OpenGraph: map[string]string{
+			"og:title": "Crawler", "og:type": "website", "og:image": "https://example.com/og.png", "og:url": "https://example.com/docs/",
+		},
+		Twitter:  map[string]string{"twitter:card": "summary_large_image"},
+		Viewport: "width=device-width, initial-scale=1",
+		Charset:  "utf-8",
+		Lang:     "en",
+		Icons: []models.Icon{
+			{Rel: "icon", URL: "https://example.com/favicon.ico"},
+			{Rel: "apple-touch-icon", URL: "https://example.com/apple-touch-icon.png", Sizes: "180x180"},
+		},
+	}
+
+	if got := ExtractMetadata(doc, "https://example.com/docs/?ref=nav"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestExtractMetadata_Issues(t *testing.T) {
+	const head = `<meta charset="utf-8"><meta name="viewport" content="width=device-width">` +
+		`<title>A title that is long enough to pass</title>` +
+		`<meta name="description" content="A description that is long enough to pass the check for short descriptions.">` +
+		`<link rel="canonical" href="/">`
+
+	tests := []struct {
+		name        string
+		htmlStr     string
+		contentType string
+		// issues are the expected field:code pairs
+		issues []string
+	}{
+		{"Valid", `<html lang="en"><head>` + head + `</head></html>`, "", nil},
+		{
+			"Empty page",
+			`<html><head></head></html>`,
+			"",
+			[]string{"title:missing", "description:missing", "canonical:missing", "viewport:missing", "charset:missing", "lang:missing"},
+		},
+		{
+			"Charset from the header",
+			`<html lang="en"><head><meta name="viewport" content="width=device-width"><title>A title that is long enough to pass</title>` +
+				`<meta name="description" content="A description that is long enough to pass the check for short descriptions.">` +
+				`<link rel="canonical" href="/"></head></html>`,
+			"text/html; charset=ISO-8859-1",
+			nil,
+		},
+		{
+			"Lengths",
+			`<html lang="en"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width"><title>Home</title>` +
+				`<meta name="description" content="` + strings.Repeat("ü", 161) + `"><link rel="canonical" href="/"></head></html>`,
+			"",
+			[]string{"title:too_short", "description:too_long"},
+		},
+		{
+			"Duplicates",
+			`<html lang="en"><head>` + head + `<meta name="description" content="Other"><link rel="canonical" href="/other">` +
+				`<link rel="alternate" hreflang="en" href="/en"><link rel="alternate" hreflang="EN" href="/en-us"></head></html>`,
+			"",
+			[]string{"description:multiple", "canonical:multiple", "hreflang:duplicate"},
+		},
+		{
+			"Conflicting robots",
+			`<html lang="en"><head>` + head + `<meta name="robots" content="index,follow"><meta name="robots" content="noindex"></head></html>`,
+			"",
+			[]string{"robots:conflict"},
+		},
+		{
+			"Social tags",
+			`<html lang="en"><head>` + head + `<meta property="og:title" content="Home"><meta name="twitter:card" content="large"></head></html>`,
+			"",
+			[]string{"open_graph:incomplete", "twitter:invalid_card"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.htmlStr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, _ := url.Parse("https://example.com/")
+
+			var issues []string
+			for _, issue := range extractMetadata(doc, page, Canonicalizer{}, tt.contentType).Issues {
+				issues = append(issues, issue.Field+":"+issue.Code)
+			}
+			if !reflect.DeepEqual(issues, tt.issues) {
+				t.Errorf("Expected issues %v, got %v", tt.issues, issues)
+			}
+		})
+	}
+}
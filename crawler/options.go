@@ -0,0 +1,755 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"go-webcrawler/models"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultUserAgent      = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+	DefaultConnectTimeout = 5 * time.Second
+	DefaultReadTimeout    = 10 * time.Second
+	DefaultMaxRedirects   = 10
+	DefaultMaxAttempts    = 3
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 30 * time.Second
+
+	DefaultHostRequestsPerSecond = 5
+	DefaultMaxHostConnections    = 2
+
+	// DefaultChromeURL is where Chrome listens when started with --remote-debugging-port=9222
+	DefaultChromeURL  = "http://127.0.0.1:9222"
+	DefaultRenderIdle = 500 * time.Millisecond
+)
+
+// DefaultHeaders are just enough to get past basic bot detection, see ASSUMPTIONS.md
+var DefaultHeaders = map[string]string{
+	"Accept":     "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+	"Connection": "keep-alive",
+}
+
+var (
+	DefaultRetryStatuses = MustParseStatusSet("408,429,500,502-504")
+	// DefaultRetryErrors leave out DNS and TLS failures, which rarely fix themselves
+	DefaultRetryErrors = []models.LinkStatus{models.LinkTimeout, models.LinkNetworkError}
+)
+
+type Options struct {
+	UserAgent string
+	// Headers are sent with every request, on top of DefaultHeaders
+	Headers map[string]string
+	// Timeout limits a whole page fetch, including reading the body
+	Timeout time.Duration
+	// ConnectTimeout limits the TCP connect and the TLS handshake
+	ConnectTimeout time.Duration
+	// ReadTimeout limits the wait for response headers once the request is sent
+	ReadTimeout time.Duration
+	// LinkTimeout limits a single link check
+	LinkTimeout time.Duration
+	// MaxRedirects limits how many redirects a page fetch follows, -1 follows none
+	MaxRedirects int
+	// SuccessStatuses are the final status codes that make a crawl successful
+	SuccessStatuses StatusSet
+	// ParseStatuses are the status codes whose HTML is analyzed
+	ParseStatuses StatusSet
+	// AnalyzeErrorPages also analyzes the HTML of unsuccessful responses, like a custom 404 page
+	AnalyzeErrorPages bool
+	// MaxAttempts is how often a page fetch is tried, 1 disables retries
+	MaxAttempts int
+	// RetryBaseDelay is the backoff after the first attempt, it doubles with every retry
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the backoff. A longer Retry-After gives up instead of waiting.
+	RetryMaxDelay time.Duration
+	// RetryStatuses are the status codes worth another attempt
+	RetryStatuses StatusSet
+	// RetryErrors are the kinds of network errors worth another attempt
+	RetryErrors []models.LinkStatus
+	// HostRequestsPerSecond limits requests to one host, page fetches and link checks together. -1 disables it.
+	HostRequestsPerSecond float64
+	// MaxHostConnections limits concurrent requests to one host, -1 disables it
+	MaxHostConnections int
+	// MinHostDelay is the least time between two requests to one host. robots.txt
+	// Crawl-delay is used instead when it is longer.
+	MinHostDelay time.Duration
+	// Limiter is shared with other crawls so their requests to a host add up.
+	// The host limits above can only make a crawl wait longer than Limiter does.
+	// Each crawl gets its own limiter when it is nil.
+	Limiter *HostLimiter
+	// ProxyURL is an http://, https:// or socks5:// proxy. When empty the
+	// HTTP_PROXY and HTTPS_PROXY environment variables are used.
+	ProxyURL           string
+	InsecureSkipVerify bool
+	// CABundle is a PEM file with extra trusted certificate authorities
+	CABundle string
+	// IgnoreRobots skips robots.txt, meant for auditing sites you own
+	IgnoreRobots bool
+	// StripTrackingParams removes utm_* and click ID parameters before links are compared
+	StripTrackingParams bool
+	// LinkScope decides which links count as internal: ScopeHost,
+	// ScopeSubdomains or ScopeDomain, the default
+	LinkScope Scope
+	// Render loads pages in a headless Chrome and analyzes the DOM after scripts ran
+	Render bool
+	// ChromeURL is the DevTools HTTP endpoint of the Chrome used for rendering
+	ChromeURL string
+	// RenderWaitSelector waits for a CSS selector to match instead of for the network to go idle
+	RenderWaitSelector string
+	// RenderIdle is how long the network must be quiet before a rendered page is analyzed
+	RenderIdle time.Duration
+	// Analyzers limits the analyzers that run to these names, all run when it is empty
+	Analyzers []string
+	// DisabledAnalyzers are analyzers that don't run
+	DisabledAnalyzers []string
+	// Registry has the analyzers to pick from, DefaultRegistry when nil
+	Registry *Registry
+}
+
+func (o Options) registry() *Registry {
+	if o.Registry == nil {
+		return DefaultRegistry
+	}
+	return o.Registry
+}
+
+// AnalyzerNames returns the analyzers of the registry in use, whether they run or not
+func (o Options) AnalyzerNames() []string {
+	return o.registry().Names()
+}
+
+func DefaultOptions() Options {
+	return Options{
+		UserAgent:       DefaultUserAgent,
+		Headers:         maps.Clone(DefaultHeaders),
+		Timeout:         RequestTimeout,
+		ConnectTimeout:  DefaultConnectTimeout,
+		ReadTimeout:     DefaultReadTimeout,
+		LinkTimeout:     LinkCheckTimeout,
+		MaxRedirects:    DefaultMaxRedirects,
+		SuccessStatuses: DefaultSuccessStatuses,
+		ParseStatuses:   DefaultParseStatuses,
+		MaxAttempts:     DefaultMaxAttempts,
+		RetryBaseDelay:  DefaultRetryBaseDelay,
+		RetryMaxDelay:   DefaultRetryMaxDelay,
+		RetryStatuses:   DefaultRetryStatuses,
+		RetryErrors:     DefaultRetryErrors,
+
+		HostRequestsPerSecond: DefaultHostRequestsPerSecond,
+		MaxHostConnections:    DefaultMaxHostConnections,
+		LinkScope:             ScopeDomain,
+		ChromeURL:             DefaultChromeURL,
+		RenderIdle:            DefaultRenderIdle,
+	}
+}
+
+// withDefaults fills in every unset field from DefaultOptions
+func (o Options) withDefaults() Options {
+	defaults := DefaultOptions()
+
+	if o.UserAgent == "" {
+		o.UserAgent = defaults.UserAgent
+	}
+	headers := defaults.Headers
+	maps.Copy(headers, o.Headers)
+	o.Headers = headers
+	if o.Timeout <= 0 {
+		o.Timeout = defaults.Timeout
+	}
+	if o.ConnectTimeout <= 0 {
+		o.ConnectTimeout = defaults.ConnectTimeout
+	}
+	if o.ReadTimeout <= 0 {
+		o.ReadTimeout = defaults.ReadTimeout
+	}
+	if o.LinkTimeout <= 0 {
+		o.LinkTimeout = defaults.LinkTimeout
+	}
+	if o.MaxRedirects == 0 {
+		o.MaxRedirects = defaults.MaxRedirects
+	}
+	if o.SuccessStatuses == nil {
+		o.SuccessStatuses = defaults.SuccessStatuses
+	}
+	if o.ParseStatuses == nil {
+		o.ParseStatuses = defaults.ParseStatuses
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = defaults.MaxAttempts
+	}
+	if o.RetryBaseDelay <= 0 {
+		o.RetryBaseDelay = defaults.RetryBaseDelay
+	}
+	if o.RetryMaxDelay <= 0 {
+		o.RetryMaxDelay = defaults.RetryMaxDelay
+	}
+	if o.RetryStatuses == nil {
+		o.RetryStatuses = defaults.RetryStatuses
+	}
+	if o.RetryErrors == nil {
+		o.RetryErrors = defaults.RetryErrors
+	}
+	if o.HostRequestsPerSecond == 0 {
+		o.HostRequestsPerSecond = defaults.HostRequestsPerSecond
+	}
+	if o.MaxHostConnections == 0 {
+		o.MaxHostConnections = defaults.MaxHostConnections
+	}
+	if o.LinkScope == "" {
+		o.LinkScope = defaults.LinkScope
+	}
+	if o.ChromeURL == "" {
+		o.ChromeURL = defaults.ChromeURL
+	}
+	if o.RenderIdle <= 0 {
+		o.RenderIdle = defaults.RenderIdle
+	}
+
+	return o
+}
+
+func (o Options) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if o.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(o.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return http.ProxyURL(proxyURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", proxyURL.Scheme)
+	}
+}
+
+func (o Options) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CABundle == "" {
+		return config, nil
+	}
+
+	pem, err := os.ReadFile(o.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CABundle)
+	}
+	config.RootCAs = pool
+
+	return config, nil
+}
+
+// Duration reads durations like "10s" or "1m30s" from JSON, plain numbers are seconds
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		*d = Duration(v * float64(time.Second))
+	case string:
+		parsed, err := parseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
+
+// Overrides changes some Options and leaves the rest alone. It is the format
+// of the config file and of per-request options in the API.
+type Overrides struct {
+	UserAgent             *string             `json:"user_agent,omitempty"`
+	Headers               map[string]string   `json:"headers,omitempty"`
+	Timeout               *Duration           `json:"timeout,omitempty"`
+	ConnectTimeout        *Duration           `json:"connect_timeout,omitempty"`
+	ReadTimeout           *Duration           `json:"read_timeout,omitempty"`
+	LinkTimeout           *Duration           `json:"link_timeout,omitempty"`
+	MaxRedirects          *int                `json:"max_redirects,omitempty"`
+	SuccessStatuses       StatusSet           `json:"success_statuses,omitempty"`
+	ParseStatuses         StatusSet           `json:"parse_statuses,omitempty"`
+	AnalyzeErrorPages     *bool               `json:"analyze_error_pages,omitempty"`
+	MaxAttempts           *int                `json:"max_attempts,omitempty"`
+	RetryBaseDelay        *Duration           `json:"retry_base_delay,omitempty"`
+	RetryMaxDelay         *Duration           `json:"retry_max_delay,omitempty"`
+	RetryStatuses         StatusSet           `json:"retry_statuses,omitempty"`
+	RetryErrors           []models.LinkStatus `json:"retry_errors,omitempty"`
+	HostRequestsPerSecond *float64            `json:"host_rps,omitempty"`
+	MaxHostConnections    *int                `json:"max_host_connections,omitempty"`
+	MinHostDelay          *Duration           `json:"min_host_delay,omitempty"`
+	ProxyURL              *string             `json:"proxy_url,omitempty"`
+	InsecureSkipVerify    *bool               `json:"insecure_skip_verify,omitempty"`
+	CABundle              *string             `json:"ca_bundle,omitempty"`
+	IgnoreRobots          *bool               `json:"ignore_robots,omitempty"`
+	StripTrackingParams   *bool               `json:"strip_tracking_params,omitempty"`
+	LinkScope             *Scope              `json:"link_scope,omitempty"`
+	Render                *bool               `json:"render,omitempty"`
+	ChromeURL             *string             `json:"chrome_url,omitempty"`
+	RenderWaitSelector    *string             `json:"render_wait_selector,omitempty"`
+	RenderIdle            *Duration           `json:"render_idle,omitempty"`
+	Analyzers             []string            `json:"analyzers,omitempty"`
+	DisabledAnalyzers     []string            `json:"disabled_analyzers,omitempty"`
+}
+
+func (o Options) Apply(ov Overrides) Options {
+	if ov.UserAgent != nil {
+		o.UserAgent = *ov.UserAgent
+	}
+	if len(ov.Headers) > 0 {
+		headers := maps.Clone(o.Headers)
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		maps.Copy(headers, ov.Headers)
+		o.Headers = headers
+	}
+	if ov.Timeout != nil {
+		o.Timeout = time.Duration(*ov.Timeout)
+	}
+	if ov.ConnectTimeout != nil {
+		o.ConnectTimeout = time.Duration(*ov.ConnectTimeout)
+	}
+	if ov.ReadTimeout != nil {
+		o.ReadTimeout = time.Duration(*ov.ReadTimeout)
+	}
+	if ov.LinkTimeout != nil {
+		o.LinkTimeout = time.Duration(*ov.LinkTimeout)
+	}
+	if ov.MaxRedirects != nil {
+		o.MaxRedirects = *ov.MaxRedirects
+	}
+	if ov.SuccessStatuses != nil {
+		o.SuccessStatuses = ov.SuccessStatuses
+	}
+	if ov.ParseStatuses != nil {
+		o.ParseStatuses = ov.ParseStatuses
+	}
+	if ov.AnalyzeErrorPages != nil {
+		o.AnalyzeErrorPages = *ov.AnalyzeErrorPages
+	}
+	if ov.MaxAttempts != nil {
+		o.MaxAttempts = *ov.MaxAttempts
+	}
+	if ov.RetryBaseDelay != nil {
+		o.RetryBaseDelay = time.Duration(*ov.RetryBaseDelay)
+	}
+	if ov.RetryMaxDelay != nil {
+		o.RetryMaxDelay = time.Duration(*ov.RetryMaxDelay)
+	}
+	if ov.RetryStatuses != nil {
+		o.RetryStatuses = ov.RetryStatuses
+	}
+	if ov.RetryErrors != nil {
+		o.RetryErrors = ov.RetryErrors
+	}
+	if ov.HostRequestsPerSecond != nil {
+		o.HostRequestsPerSecond = *ov.HostRequestsPerSecond
+	}
+	if ov.MaxHostConnections != nil {
+		o.MaxHostConnections = *ov.MaxHostConnections
+	}
+	if ov.MinHostDelay != nil {
+		o.MinHostDelay = time.Duration(*ov.MinHostDelay)
+	}
+	if ov.ProxyURL != nil {
+		o.ProxyURL = *ov.ProxyURL
+	}
+	if ov.InsecureSkipVerify != nil {
+		o.InsecureSkipVerify = *ov.InsecureSkipVerify
+	}
+	if ov.CABundle != nil {
+		o.CABundle = *ov.CABundle
+	}
+	if ov.IgnoreRobots != nil {
+		o.IgnoreRobots = *ov.IgnoreRobots
+	}
+	if ov.StripTrackingParams != nil {
+		o.StripTrackingParams = *ov.StripTrackingParams
+	}
+	if ov.LinkScope != nil {
+		o.LinkScope = *ov.LinkScope
+	}
+	if ov.Render != nil {
+		o.Render = *ov.Render
+	}
+	if ov.ChromeURL != nil {
+		o.ChromeURL = *ov.ChromeURL
+	}
+	if ov.RenderWaitSelector != nil {
+		o.RenderWaitSelector = *ov.RenderWaitSelector
+	}
+	if ov.RenderIdle != nil {
+		o.RenderIdle = time.Duration(*ov.RenderIdle)
+	}
+	if ov.Analyzers != nil {
+		o.Analyzers = ov.Analyzers
+	}
+	if ov.DisabledAnalyzers != nil {
+		o.DisabledAnalyzers = ov.DisabledAnalyzers
+	}
+	return o
+}
+
+// ConfigEnv names an optional JSON file with crawler options, read by the
+// server and by the CLI when --config isn't given
+const ConfigEnv = "CRAWLER_CONFIG"
+
+// LoadOptions starts from DefaultOptions, applies the JSON config file at path
+// (skipped when path is empty) and then the CRAWLER_* environment variables
+func LoadOptions(path string) (Options, error) {
+	opts := DefaultOptions()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read config file: %v", err)
+		}
+
+		var ov Overrides
+		if err := json.Unmarshal(data, &ov); err != nil {
+			return opts, fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+		opts = opts.Apply(ov)
+	}
+
+	ov, err := overridesFromEnv(os.LookupEnv)
+	if err != nil {
+		return opts, err
+	}
+	opts = opts.Apply(ov)
+
+	return opts, opts.Validate()
+}
+
+func overridesFromEnv(lookup func(string) (string, bool)) (Overrides, error) {
+	var ov Overrides
+	var err error
+
+	str := func(name string, target **string) {
+		if value, ok := lookup(name); ok {
+			*target = &value
+		}
+	}
+	boolean := func(name string, target **bool) {
+		if value, ok := lookup(name); ok && err == nil {
+			parsed, parseErr := strconv.ParseBool(value)
+			if parseErr != nil {
+				err = fmt.Errorf("invalid %s: %q is not a boolean", name, value)
+				return
+			}
+			*target = &parsed
+		}
+	}
+	integer := func(name string, target **int) {
+		if value, ok := lookup(name); ok && err == nil {
+			parsed, parseErr := strconv.Atoi(value)
+			if parseErr != nil {
+				err = fmt.Errorf("invalid %s: %q is not a number", name, value)
+				return
+			}
+			*target = &parsed
+		}
+	}
+	number := func(name string, target **float64) {
+		if value, ok := lookup(name); ok && err == nil {
+			parsed, parseErr := strconv.ParseFloat(value, 64)
+			if parseErr != nil {
+				err = fmt.Errorf("invalid %s: %q is not a number", name, value)
+				return
+			}
+			*target = &parsed
+		}
+	}
+	statuses := func(name string, target *StatusSet) {
+		if value, ok := lookup(name); ok && err == nil {
+			parsed, parseErr := ParseStatusSet(value)
+			if parseErr != nil {
+				err = fmt.Errorf("invalid %s: %v", name, parseErr)
+				return
+			}
+			*target = parsed
+		}
+	}
+	duration := func(name string, target **Duration) {
+		if value, ok := lookup(name); ok && err == nil {
+			parsed, parseErr := parseDuration(value)
+			if parseErr != nil {
+				err = fmt.Errorf("invalid %s: %v", name, parseErr)
+				return
+			}
+			d := Duration(parsed)
+			*target = &d
+		}
+	}
+
+	str("CRAWLER_USER_AGENT", &ov.UserAgent)
+	str("CRAWLER_PROXY_URL", &ov.ProxyURL)
+	str("CRAWLER_CA_BUNDLE", &ov.CABundle)
+	if value, ok := lookup("CRAWLER_LINK_SCOPE"); ok {
+		scope := Scope(value)
+		ov.LinkScope = &scope
+	}
+	boolean("CRAWLER_INSECURE_SKIP_VERIFY", &ov.InsecureSkipVerify)
+	boolean("CRAWLER_IGNORE_ROBOTS", &ov.IgnoreRobots)
+	boolean("CRAWLER_STRIP_TRACKING_PARAMS", &ov.StripTrackingParams)
+	duration("CRAWLER_TIMEOUT", &ov.Timeout)
+	duration("CRAWLER_CONNECT_TIMEOUT", &ov.ConnectTimeout)
+	duration("CRAWLER_READ_TIMEOUT", &ov.ReadTimeout)
+	duration("CRAWLER_LINK_TIMEOUT", &ov.LinkTimeout)
+	integer("CRAWLER_MAX_REDIRECTS", &ov.MaxRedirects)
+	statuses("CRAWLER_SUCCESS_STATUSES", &ov.SuccessStatuses)
+	statuses("CRAWLER_PARSE_STATUSES", &ov.ParseStatuses)
+	boolean("CRAWLER_ANALYZE_ERROR_PAGES", &ov.AnalyzeErrorPages)
+	integer("CRAWLER_MAX_ATTEMPTS", &ov.MaxAttempts)
+	duration("CRAWLER_RETRY_BASE_DELAY", &ov.RetryBaseDelay)
+	duration("CRAWLER_RETRY_MAX_DELAY", &ov.RetryMaxDelay)
+	statuses("CRAWLER_RETRY_STATUSES", &ov.RetryStatuses)
+	number("CRAWLER_HOST_RPS", &ov.HostRequestsPerSecond)
+	integer("CRAWLER_MAX_HOST_CONNECTIONS", &ov.MaxHostConnections)
+	duration("CRAWLER_MIN_HOST_DELAY", &ov.MinHostDelay)
+	boolean("CRAWLER_RENDER", &ov.Render)
+	str("CRAWLER_CHROME_URL", &ov.ChromeURL)
+	str("CRAWLER_RENDER_WAIT_SELECTOR", &ov.RenderWaitSelector)
+	duration("CRAWLER_RENDER_IDLE", &ov.RenderIdle)
+	if value, ok := lookup("CRAWLER_RETRY_ERRORS"); ok && err == nil {
+		ov.RetryErrors = []models.LinkStatus{}
+		for _, kind := range strings.Split(value, ",") {
+			if kind = strings.TrimSpace(kind); kind != "" {
+				ov.RetryErrors = append(ov.RetryErrors, models.LinkStatus(kind))
+			}
+		}
+	}
+	if value, ok := lookup("CRAWLER_ANALYZERS"); ok {
+		ov.Analyzers = SplitList(value)
+	}
+	if value, ok := lookup("CRAWLER_DISABLED_ANALYZERS"); ok {
+		ov.DisabledAnalyzers = SplitList(value)
+	}
+
+	// CRAWLER_HEADERS holds "Name: value" pairs separated by newlines or semicolons
+	if value, ok := lookup("CRAWLER_HEADERS"); ok && err == nil {
+		ov.Headers, err = ParseHeaders(strings.FieldsFunc(value, func(r rune) bool {
+			return r == '\n' || r == ';'
+		}))
+	}
+
+	return ov, err
+}
+
+// SplitList splits a comma separated list like "title, links", leaving out empty entries
+func SplitList(value string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// ParseHeaders turns "Name: value" lines into a header map
+func ParseHeaders(lines []string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid header %q, use \"Name: value\"", line)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// Validate checks the settings that can't be checked while parsing them
+func (o Options) Validate() error {
+	for _, kind := range o.RetryErrors {
+		switch kind {
+		case models.LinkTimeout, models.LinkDNSError, models.LinkTLSError, models.LinkNetworkError:
+		default:
+			return fmt.Errorf("unknown retry error %q, use %s, %s, %s or %s", kind,
+				models.LinkTimeout, models.LinkDNSError, models.LinkTLSError, models.LinkNetworkError)
+		}
+	}
+
+	switch o.LinkScope {
+	case "", ScopeHost, ScopeSubdomains, ScopeDomain:
+	default:
+		return fmt.Errorf("unknown link scope %q, use %s, %s or %s", o.LinkScope, ScopeHost, ScopeSubdomains, ScopeDomain)
+	}
+
+	if o.Render {
+		if u, err := url.Parse(o.withDefaults().ChromeURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid chrome URL %q, use the DevTools endpoint like %s", o.ChromeURL, DefaultChromeURL)
+		}
+	}
+
+	if _, err := o.registry().selected(o.Analyzers, o.DisabledAnalyzers); err != nil {
+		return err
+	}
+
+	if _, err := o.proxy(); err != nil {
+		return err
+	}
+	_, err := o.tlsConfig()
+	return err
+}
+
+// maxTransports limits how many differently configured transports are kept
+const maxTransports = 16
+
+// transportKey is everything that makes two transports behave differently.
+// Headers are added on top by headerTransport and don't need their own.
+type transportKey struct {
+	proxyURL           string
+	insecureSkipVerify bool
+	caBundle           string
+	connectTimeout     time.Duration
+	readTimeout        time.Duration
+}
+
+// transports are shared by all crawls with the same settings, so their idle
+// connections are reused instead of piling up with every crawl
+var transports = struct {
+	sync.Mutex
+	byKey map[transportKey]*http.Transport
+	// order is oldest first, the oldest is dropped when there are too many
+	order []transportKey
+}{byKey: make(map[transportKey]*http.Transport)}
+
+func (o Options) newTransport() (http.RoundTripper, error) {
+	o = o.withDefaults()
+	base, err := o.sharedTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	return &headerTransport{
+		base:      base,
+		userAgent: o.UserAgent,
+		headers:   o.Headers,
+	}, nil
+}
+
+func (o Options) sharedTransport() (*http.Transport, error) {
+	key := transportKey{
+		proxyURL:           o.ProxyURL,
+		insecureSkipVerify: o.InsecureSkipVerify,
+		caBundle:           o.CABundle,
+		connectTimeout:     o.ConnectTimeout,
+		readTimeout:        o.ReadTimeout,
+	}
+
+	transports.Lock()
+	defer transports.Unlock()
+
+	if transport, ok := transports.byKey[key]; ok {
+		return transport, nil
+	}
+
+	proxy, err := o.proxy()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   o.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   o.ConnectTimeout,
+		ResponseHeaderTimeout: o.ReadTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	if len(transports.order) == maxTransports {
+		// Crawls still using it keep working, its connections just aren't kept afterwards
+		oldest := transports.order[0]
+		transports.byKey[oldest].CloseIdleConnections()
+		delete(transports.byKey, oldest)
+		transports.order = transports.order[1:]
+	}
+	transports.byKey[key] = transport
+	transports.order = append(transports.order, key)
+
+	return transport, nil
+}
+
+// headerTransport adds the configured headers to every request that has not set them
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	headers   map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	for name, value := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,195 @@
+package crawler
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOptionsApply(t *testing.T) {
+	ua := "test-agent"
+	timeout := Duration(3 * time.Second)
+	opts := DefaultOptions().Apply(Overrides{
+		UserAgent: &ua,
+		Headers:   map[string]string{"X-Test": "1"},
+		Timeout:   &timeout,
+	})
+
+	if opts.UserAgent != ua {
+		t.Errorf("Expected user agent %q, got %q", ua, opts.UserAgent)
+	}
+	if opts.Timeout != 3*time.Second {
+		t.Errorf("Expected timeout 3s, got %v", opts.Timeout)
+	}
+	if opts.Headers["X-Test"] != "1" || opts.Headers["Accept"] == "" {
+		t.Errorf("Expected headers to be merged with the defaults, got %v", opts.Headers)
+	}
+	if DefaultHeaders["X-Test"] != "" {
+		t.Error("Apply must not modify DefaultHeaders")
+	}
+	if opts.ReadTimeout != DefaultReadTimeout {
+		t.Errorf("Expected unset fields to keep their value, got read timeout %v", opts.ReadTimeout)
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"user_agent": "from-file", "timeout": "20s", "connect_timeout": 2, "proxy_url": "socks5://127.0.0.1:1080", "analyzers": ["title"]}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CRAWLER_USER_AGENT", "from-env")
+	t.Setenv("CRAWLER_HEADERS", "X-One: 1; X-Two: 2")
+	t.Setenv("CRAWLER_DISABLED_ANALYZERS", "links, accessibility")
+
+	opts, err := LoadOptions(path)
+	if err != nil {
+		t.Fatalf("LoadOptions failed: %v", err)
+	}
+
+	if opts.UserAgent != "from-env" {
+		t.Errorf("Expected the environment to win over the file, got %q", opts.UserAgent)
+	}
+	if opts.Timeout != 20*time.Second || opts.ConnectTimeout != 2*time.Second {
+		t.Errorf("Expected timeouts from the file, got %v and %v", opts.Timeout, opts.ConnectTimeout)
+	}
+	if opts.ProxyURL != "socks5://127.0.0.1:1080" {
+		t.Errorf("Expected the SOCKS5 proxy from the file, got %q", opts.ProxyURL)
+	}
+	if opts.Headers["X-One"] != "1" || opts.Headers["X-Two"] != "2" {
+		t.Errorf("Expected headers from the environment, got %v", opts.Headers)
+	}
+	if !reflect.DeepEqual(opts.Analyzers, []string{"title"}) || !reflect.DeepEqual(opts.DisabledAnalyzers, []string{"links", "accessibility"}) {
+		t.Errorf("Expected analyzers from the file and the environment, got %v and %v", opts.Analyzers, opts.DisabledAnalyzers)
+	}
+}
+
+func TestLoadOptions_Errors(t *testing.T) {
+	dir := t.TempDir()
+	badJSON := filepath.Join(dir, "bad.json")
+	os.WriteFile(badJSON, []byte(`{"timeout": true}`), 0o600)
+	badProxy := filepath.Join(dir, "proxy.json")
+	os.WriteFile(badProxy, []byte(`{"proxy_url": "ftp://proxy"}`), 0o600)
+
+	tests := []struct {
+		name string
+		path string
+		env  map[string]string
+	}{
+		{"Missing file", filepath.Join(dir, "missing.json"), nil},
+		{"Invalid duration", badJSON, nil},
+		{"Unsupported proxy", badProxy, nil},
+		{"Invalid env bool", "", map[string]string{"CRAWLER_INSECURE_SKIP_VERIFY": "maybe"}},
+		{"Invalid env header", "", map[string]string{"CRAWLER_HEADERS": "no colon"}},
+		{"Unknown link scope", "", map[string]string{"CRAWLER_LINK_SCOPE": "prefix"}},
+		{"Unknown analyzer", "", map[string]string{"CRAWLER_ANALYZERS": "title,spelling"}},
+		{"Missing CA bundle", "", map[string]string{"CRAWLER_CA_BUNDLE": filepath.Join(dir, "missing.pem")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if _, err := LoadOptions(tt.path); err == nil {
+				t.Error("Expected LoadOptions to fail")
+			}
+		})
+	}
+}
+
+func TestNewTransport_Shared(t *testing.T) {
+	base := func(opts Options) http.RoundTripper {
+		transport, err := opts.newTransport()
+		if err != nil {
+			t.Fatalf("newTransport failed: %v", err)
+		}
+		return transport.(*headerTransport).base
+	}
+
+	first := base(Options{UserAgent: "first"})
+	if base(Options{UserAgent: "second", Headers: map[string]string{"X-Test": "1"}}) != first {
+		t.Error("Expected crawls that only differ in headers to share a transport")
+	}
+	if base(Options{ReadTimeout: 3 * time.Second}) == first {
+		t.Error("Expected a different read timeout to get its own transport")
+	}
+}
+
+func TestCrawlURL_CustomHeaders(t *testing.T) {
+	var userAgent, custom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			userAgent = r.Header.Get("User-Agent")
+			custom = r.Header.Get("X-Custom")
+		}
+		w.Write([]byte(`<html><head><title>Headers</title></head></html>`))
+	}))
+	defer server.Close()
+
+	opts := Options{
+		UserAgent: "custom-agent/1.0",
+		Headers:   map[string]string{"X-Custom": "yes"},
+	}
+	result := CrawlURLWithOptions(context.Background(), server.URL, opts)
+
+	if !result.Success {
+		t.Fatalf("Expected successful crawl, got error: %s", result.Error)
+	}
+	if userAgent != "custom-agent/1.0" {
+		t.Errorf("Expected custom user agent, got %q", userAgent)
+	}
+	if custom != "yes" {
+		t.Errorf("Expected X-Custom header, got %q", custom)
+	}
+}
+
+func TestCrawlURL_TLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Secure</title></head></html>`))
+	}))
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundle, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		success bool
+	}{
+		{"Untrusted certificate", Options{IgnoreRobots: true}, false},
+		{"Verification disabled", Options{IgnoreRobots: true, InsecureSkipVerify: true}, true},
+		{"CA bundle", Options{IgnoreRobots: true, CABundle: caBundle}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CrawlURLWithOptions(context.Background(), server.URL, tt.opts)
+			if result.Success != tt.success {
+				t.Errorf("Expected success %v, got %v (error: %s)", tt.success, result.Success, result.Error)
+			}
+		})
+	}
+}
+
+func TestCrawlURL_InvalidOptions(t *testing.T) {
+	result := CrawlURLWithOptions(context.Background(), "https://example.com", Options{ProxyURL: "ftp://proxy"})
+
+	if result.Success {
+		t.Error("Expected crawl with an invalid proxy to fail")
+	}
+	if result.Error == "" {
+		t.Error("Expected an error message for invalid options")
+	}
+}
@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"fmt"
+	"go-webcrawler/models"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// headingMaxLength is the number of characters from which a heading reads
+// like a paragraph
+const headingMaxLength = 70
+
+// ExtractOutline nests the page's headings by level and checks their structure
+func ExtractOutline(n *html.Node) models.Outline {
+	var flat []models.Heading
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if level := headingLevel(n.Data); level > 0 {
+				flat = append(flat, models.Heading{Level: level, Text: anchorText(n), Path: domPath(n)})
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return models.Outline{Headings: nestHeadings(flat), Issues: checkHeadings(flat)}
+}
+
+// nestHeadings makes each heading a child of the closest heading before it
+// with a lower level
+func nestHeadings(flat []models.Heading) []models.Heading {
+	var nest func(i, parent int) ([]models.Heading, int)
+	nest = func(i, parent int) ([]models.Heading, int) {
+		var headings []models.Heading
+		for i < len(flat) && flat[i].Level > parent {
+			heading := flat[i]
+			heading.Children, i = nest(i+1, heading.Level)
+			headings = append(headings, heading)
+		}
+		return headings, i
+	}
+	headings, _ := nest(0, 0)
+	return headings
+}
+
+func checkHeadings(flat []models.Heading) []models.HeadingIssue {
+	var issues []models.HeadingIssue
+	h1s := 0
+	previous := 0
+
+	for _, heading := range flat {
+		if heading.Level == 1 {
+			h1s++
+		}
+		// The first heading may have any level, a missing h1 is reported on its own
+		if previous > 0 && heading.Level > previous+1 {
+			issues = append(issues, models.HeadingIssue{
+				Code:    "skipped_level",
+				Message: fmt.Sprintf("h%d follows h%d, skipping a level", heading.Level, previous),
+				Path:    heading.Path,
+			})
+		}
+		previous = heading.Level
+
+		if heading.Text == "" {
+			issues = append(issues, models.HeadingIssue{Code: "empty", Message: fmt.Sprintf("Empty h%d", heading.Level), Path: heading.Path})
+		} else if length := utf8.RuneCountInString(heading.Text); length > headingMaxLength {
+			issues = append(issues, models.HeadingIssue{
+				Code:    "too_long",
+				Message: fmt.Sprintf("h%d has %d characters, headings should stay under %d", heading.Level, length, headingMaxLength),
+				Path:    heading.Path,
+			})
+		}
+	}
+
+	switch {
+	case h1s == 0:
+		issues = append(issues, models.HeadingIssue{Code: "missing_h1", Message: "No h1"})
+	case h1s > 1:
+		issues = append(issues, models.HeadingIssue{Code: "multiple_h1", Message: fmt.Sprintf("%d h1 headings, the page should have one", h1s)})
+	}
+	return issues
+}
+
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
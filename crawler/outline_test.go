@@ -0,0 +1,74 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractOutline(t *testing.T) {
+	htmlStr := `<html><body>
+		<h1>Guide</h1>
+		<section><h2>Install</h2><h3>Linux</h3><h3>macOS</h3></section>
+		<h2>Usage <small>v2</small></h2>
+		<h1><img src="logo.png" alt="Appendix"></h1>
+	</body></html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.Heading{
+		{Level: 1, Text: "Guide", Path: "html > body > h1:nth-of-type(1)", Children: []models.Heading{
+			{Level: 2, Text: "Install", Path: "html > body > section > h2", Children: []models.Heading{
+				{Level: 3, Text: "Linux", Path: "html > body > section > h3:nth-of-type(1)"},
+				{Level: 3, Text: "macOS", Path: "html > body > section > h3:nth-of-type(2)"},
+			}},
+			{Level: 2, Text: "Usage v2", Path: "html > body > h2"},
+		}},
+		{Level: 1, Text: "Appendix", Path: "html > body > h1:nth-of-type(2)"},
+	}
+
+	outline := ExtractOutline(doc)
+	if !reflect.DeepEqual(outline.Headings, want) {
+		t.Errorf("Expected %+v, got %+v", want, outline.Headings)
+	}
+}
+
+func TestExtractOutline_Issues(t *testing.T) {
+	tests := []struct {
+		name    string
+		htmlStr string
+		// issues are the expected codes in order
+		issues []string
+	}{
+		{"Valid", `<h1>Title</h1><h2>Section</h2><h3>Part</h3><h2>Next</h2>`, nil},
+		{"No headings", `<p>Text</p>`, []string{"missing_h1"}},
+		{"Starts at h2", `<h2>Section</h2><h3>Part</h3>`, []string{"missing_h1"}},
+		{"Multiple h1", `<h1>One</h1><h1>Two</h1>`, []string{"multiple_h1"}},
+		{"Skipped level", `<h1>Title</h1><h2>Section</h2><h4>Detail</h4><h2>Back up</h2>`, []string{"skipped_level"}},
+		{"Empty", `<h1>Title</h1><h2>  </h2>`, []string{"empty"}},
+		{"Too long", `<h1>` + strings.Repeat("word ", 15) + `</h1>`, []string{"too_long"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.htmlStr))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var issues []string
+			for _, issue := range ExtractOutline(doc).Issues {
+				issues = append(issues, issue.Code)
+			}
+			if !reflect.DeepEqual(issues, tt.issues) {
+				t.Errorf("Expected issues %v, got %v", tt.issues, issues)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package crawler
 
 import (
+	"go-webcrawler/models"
+	"net/url"
+	"regexp"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -85,90 +88,330 @@ func countHeadings(n *html.Node, headings map[string]int) {
 	}
 }
 
-func DetectLoginForm(n *html.Node) bool {
-	return hasLoginForm(n)
+func ExtractLinks(n *html.Node, baseURL string) (int, int) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return 0, 0
+	}
+
+	internal, external, _ := countLinks(resolveLinks(n, documentBase(n, base), base, Canonicalizer{}, ScopeDomain))
+	return internal, external
 }
 
-func hasLoginForm(n *html.Node) bool {
-	if n.Type == html.ElementNode && n.Data == "form" {
-		if isLoginForm(n) {
-			return true
+// countLinks counts internal and external links and the other categories,
+// duplicates included. Only links to other pages count, assets are left to countResources.
+func countLinks(links []models.Link) (int, int, map[models.LinkCategory]int) {
+	internal := 0
+	external := 0
+	var other map[models.LinkCategory]int
+
+	for _, link := range links {
+		if !link.Navigation() {
+			continue
+		}
+		switch link.Category {
+		case models.CategoryInternal:
+			internal++
+		case models.CategoryExternal:
+			external++
+		default:
+			if other == nil {
+				other = make(map[models.LinkCategory]int)
+			}
+			other[link.Category]++
 		}
 	}
+	return internal, external, other
+}
 
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if hasLoginForm(c) {
-			return true
+// countResources counts the links that don't lead to another page by what they load
+func countResources(links []models.Link) map[models.ResourceType]int {
+	var resources map[models.ResourceType]int
+	for _, link := range links {
+		if link.Navigation() {
+			continue
+		}
+		if resources == nil {
+			resources = make(map[models.ResourceType]int)
 		}
+		resources[link.Resource]++
 	}
+	return resources
+}
+
+func CollectLinks(n *html.Node, baseURL string) []string {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+
+	return uniqueURLs(resolveLinks(n, documentBase(n, base), base, Canonicalizer{}, ScopeDomain))
+}
 
-	return false
+// linkAttribute is an attribute that holds a URL and what that URL loads
+type linkAttribute struct {
+	name     string
+	resource models.ResourceType
 }
 
-func isLoginForm(formNode *html.Node) bool {
-	for _, attr := range formNode.Attr {
-		if attr.Key == "id" || attr.Key == "class" {
-			value := strings.ToLower(attr.Val)
-			if strings.Contains(value, "login") || strings.Contains(value, "signin") || strings.Contains(value, "auth") {
-				return true
+// linkAttributes lists the URL attributes of each element. The resource of
+// a <link> depends on its rel and is set by linkResource.
+var linkAttributes = map[string][]linkAttribute{
+	"a":      {{"href", models.ResourcePage}},
+	"area":   {{"href", models.ResourcePage}},
+	"link":   {{"href", models.ResourceLink}},
+	"img":    {{"src", models.ResourceImage}, {"srcset", models.ResourceImage}},
+	"script": {{"src", models.ResourceScript}},
+	"iframe": {{"src", models.ResourceFrame}},
+	"source": {{"src", models.ResourceMedia}, {"srcset", models.ResourceImage}},
+	"video":  {{"src", models.ResourceMedia}, {"poster", models.ResourceImage}},
+	"audio":  {{"src", models.ResourceMedia}},
+	"track":  {{"src", models.ResourceMedia}},
+	"form":   {{"action", models.ResourceForm}},
+}
+
+// cssURL matches url() in CSS, quoted or not
+var cssURL = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)`)
+
+// resolveLinks returns every link in n in document order: the URL attributes
+// in linkAttributes and url() in style attributes. Links resolve against base,
+// which a <base href> can point anywhere, and are internal when they are in
+// scope of site, the URL of the page itself.
+func resolveLinks(n *html.Node, base, site *url.URL, canon Canonicalizer, scope Scope) []models.Link {
+	var links []models.Link
+
+	add := func(n *html.Node, attr linkAttribute, href string) {
+		link, ok := resolveHref(base, site, href, canon, scope)
+		if !ok {
+			return
+		}
+		link.Text = anchorText(n)
+		if rel := getAttribute(n, "rel"); rel != "" {
+			link.Rel = strings.Fields(strings.ToLower(rel))
+		}
+		link.Target = getAttribute(n, "target")
+		link.Resource = attr.resource
+		if n.Data == "link" && attr.name == "href" {
+			link.Resource = linkResource(link.Rel, getAttribute(n, "as"))
+		}
+		link.Element = n.Data
+		link.Attribute = attr.name
+		link.Position = len(links) + 1
+		link.Path = domPath(n)
+		links = append(links, link)
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, attr := range linkAttributes[n.Data] {
+				value := getAttribute(n, attr.name)
+				if attr.name == "srcset" {
+					for _, candidate := range srcsetURLs(value) {
+						add(n, attr, candidate)
+					}
+					continue
+				}
+				add(n, attr, value)
 			}
+			for _, href := range cssURLs(getAttribute(n, "style")) {
+				add(n, linkAttribute{"style", models.ResourceStyle}, href)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
 		}
 	}
+	walk(n)
 
-	return false
+	return links
 }
 
-func ExtractLinks(n *html.Node, baseURL string) (int, int, int) {
-	internal := 0
-	external := 0
-	inaccessible := 0
-
-	countLinks(n, baseURL, &internal, &external, &inaccessible)
-	return internal, external, inaccessible
-}
-
-func countLinks(n *html.Node, baseURL string, internal, external, inaccessible *int) {
-	if n.Type == html.ElementNode && n.Data == "a" {
-		href := getHrefAttribute(n)
-		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "mailto:") {
-			*inaccessible++
-		} else if strings.HasPrefix(href, "http") {
-			if strings.Contains(href, extractDomain(baseURL)) {
-				*internal++
-			} else {
-				*external++
+// linkResource tells what a <link> loads from its rel and, for preloads, its as attribute
+func linkResource(rel []string, as string) models.ResourceType {
+	for _, keyword := range rel {
+		switch keyword {
+		case "stylesheet":
+			return models.ResourceStylesheet
+		case "icon", "apple-touch-icon", "mask-icon":
+			return models.ResourceIcon
+		case "modulepreload":
+			return models.ResourceScript
+		case "preload", "prefetch":
+			switch strings.ToLower(as) {
+			case "style":
+				return models.ResourceStylesheet
+			case "script", "worker":
+				return models.ResourceScript
+			case "image":
+				return models.ResourceImage
+			case "font":
+				return models.ResourceFont
+			case "audio", "video", "track":
+				return models.ResourceMedia
+			case "document":
+				return models.ResourcePage
 			}
-		} else {
-			*internal++
 		}
 	}
+	return models.ResourceLink
+}
 
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		countLinks(c, baseURL, internal, external, inaccessible)
+// srcsetURLs returns the URLs of a srcset like "a.png 1x, b.png 2x"
+func srcsetURLs(srcset string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
 	}
+	return urls
 }
 
-func getHrefAttribute(n *html.Node) string {
-	for _, attr := range n.Attr {
-		if attr.Key == "href" {
-			return strings.TrimSpace(attr.Val)
+// cssURLs returns the url() values in CSS, in order
+func cssURLs(css string) []string {
+	var urls []string
+	for _, match := range cssURL.FindAllStringSubmatch(css, -1) {
+		urls = append(urls, strings.TrimSpace(match[1]+match[2]+match[3]))
+	}
+	return urls
+}
+
+// markMixedContent flags the assets and form actions an https page loads over
+// http and returns how many there are. Links to http pages are not mixed content.
+func markMixedContent(links []models.Link, page *url.URL) int {
+	if page == nil || page.Scheme != "https" {
+		return 0
+	}
+
+	count := 0
+	for i, link := range links {
+		if link.Navigation() || link.Resource == models.ResourceLink || !strings.HasPrefix(link.URL, "http://") {
+			continue
 		}
+		links[i].MixedContent = true
+		count++
 	}
-	return ""
+	return count
+}
+
+// anchorText is the visible text of a link, or the label of an image or image-only link
+func anchorText(n *html.Node) string {
+	if text := textContent(n); text != "" {
+		return text
+	}
+	if label := getAttribute(n, "aria-label"); label != "" {
+		return label
+	}
+
+	var alt string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if alt != "" {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "img" {
+			alt = getAttribute(n, "alt")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	if alt != "" {
+		return alt
+	}
+
+	return getAttribute(n, "title")
+}
+
+// uniqueURLs returns the links to check, each once
+func uniqueURLs(links []models.Link) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, link := range links {
+		if !link.Checked() {
+			continue
+		}
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			unique = append(unique, link.URL)
+		}
+	}
+	return unique
+}
+
+// resolveHref fills in the href, URL and category of a link. It returns false
+// for hrefs that point at the page itself or can't be parsed.
+func resolveHref(base, site *url.URL, href string, canon Canonicalizer, scope Scope) (models.Link, bool) {
+	if isNonNavigableHref(href) {
+		return models.Link{}, false
+	}
+	link := models.Link{Href: href, URL: href}
+
+	// Check the scheme first, data: and javascript: URLs often don't parse
+	if category, ok := schemeCategory(hrefScheme(href)); ok {
+		link.Category = category
+		return link, true
+	}
+
+	// Relative and scheme-relative (//host/path) links take the base's scheme
+	resolved, err := canon.Resolve(base, href)
+	if err != nil {
+		return models.Link{}, false
+	}
+	link.URL = resolved.String()
+	if category, ok := schemeCategory(resolved.Scheme); ok {
+		link.Category = category
+		return link, true
+	}
+
+	link.Category = models.CategoryExternal
+	if inScope(scope, site, resolved) {
+		link.Category = models.CategoryInternal
+	}
+	return link, true
 }
 
-func extractDomain(url string) string {
-	url = strings.ToLower(url)
-	if strings.HasPrefix(url, "https://") {
-		url = url[8:]
-	} else if strings.HasPrefix(url, "http://") {
-		url = url[7:]
+// hrefScheme returns the lowercase scheme of an absolute href, or "" for relative ones
+func hrefScheme(href string) string {
+	scheme, _, found := strings.Cut(href, ":")
+	if !found || scheme == "" {
+		return ""
 	}
-	if strings.HasPrefix(url, "www.") {
-		url = url[4:]
+	for i, r := range scheme {
+		isLetter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if !isLetter && (i == 0 || !(r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.')) {
+			return ""
+		}
 	}
-	if idx := strings.IndexAny(url, "/?"); idx != -1 {
-		url = url[:idx]
+	return strings.ToLower(scheme)
+}
+
+// schemeCategory returns the category of links that aren't http(s) and so aren't crawled
+func schemeCategory(scheme string) (models.LinkCategory, bool) {
+	switch scheme {
+	case "", "http", "https":
+		return "", false
+	case "mailto":
+		return models.CategoryEmail, true
+	case "tel":
+		return models.CategoryPhone, true
+	case "javascript":
+		return models.CategoryJavaScript, true
+	case "data":
+		return models.CategoryData, true
+	case "ftp", "ftps", "sftp":
+		return models.CategoryFTP, true
+	default:
+		return models.CategoryOther, true
 	}
-	return url
+}
+
+func isNonNavigableHref(href string) bool {
+	return href == "" || strings.HasPrefix(href, "#")
+}
+
+func getHrefAttribute(n *html.Node) string {
+	return getAttribute(n, "href")
 }
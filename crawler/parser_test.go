@@ -1,6 +1,10 @@
 package crawler
 
 import (
+	"go-webcrawler/models"
+	"maps"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -94,19 +98,24 @@ func TestDetectLoginForm(t *testing.T) {
 	}{
 		{
 			"Form with login ID",
-			`<html><body><form id="login-form"><input type="text"></form></body></html>`,
+			`<html><body><form id="login-form"><input type="text" name="user"><input type="password"></form></body></html>`,
 			true,
 		},
 		{
 			"Form with signin class",
-			`<html><body><form class="signin-form"><input type="text"></form></body></html>`,
+			`<html><body><form class="sign-in-form"><input type="email"><input type="password"></form></body></html>`,
 			true,
 		},
 		{
 			"Form with auth class",
-			`<html><body><form class="auth-modal"><input type="text"></form></body></html>`,
+			`<html><body><form class="auth-modal"><input type="password"><button>Continue</button></form></body></html>`,
 			true,
 		},
+		{
+			"Author form",
+			`<html><body><form class="author-search"><input type="text" name="author"></form></body></html>`,
+			false,
+		},
 		{
 			"Regular form",
 			`<html><body><form id="contact-form"><input type="text"></form></body></html>`,
@@ -134,37 +143,20 @@ func TestDetectLoginForm(t *testing.T) {
 	}
 }
 
-func TestExtractDomain(t *testing.T) {
-	tests := []struct {
-		name     string
-		url      string
-		expected string
-	}{
-		{"HTTPS with www", "https://www.doruk.com/path", "doruk.com"},
-		{"HTTP without www", "http://doruk.com", "doruk.com"},
-		{"With port", "https://doruk.com:8080/path", "doruk.com:8080"},
-		{"Subdomain", "https://api.doruk.com", "api.doruk.com"},
-		{"No protocol", "doruk.com/path", "doruk.com"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := extractDomain(tt.url)
-			if result != tt.expected {
-				t.Errorf("extractDomain(%q) = %q; want %q", tt.url, result, tt.expected)
-			}
-		})
-	}
-}
-
 func TestExtractLinks(t *testing.T) {
 	htmlStr := `
 	<html>
-		<body>
+		<head>
+			<link rel="stylesheet" href="/style.css">
+			<link rel="canonical" href="https://doruk.com/">
+			<script src="https://cdn.external.com/app.js"></script>
+		</head>
+		<body style="background: url(/bg.png)">
+			<img src="/logo.png" srcset="/logo-2x.png 2x">
 			<a href="/">Internal root</a>
 			<a href="/page">Internal page</a>
 			<a href="https://doruk.com/internal">Internal absolute</a>
-			<a href="https://doruk.com">External</a>
+			<a href="https://external.com">External</a>
 			<a href="mailto:test@doruk.com">Email</a>
 			<a href="#section">Fragment</a>
 			<a>No href</a>
@@ -176,7 +168,8 @@ func TestExtractLinks(t *testing.T) {
 		t.Fatalf("Failed to parse HTML: %v", err)
 	}
 
-	internal, external, inaccessible := ExtractLinks(doc, "https://doruk.com")
+	// Assets and other <link> elements are not links to pages
+	internal, external := ExtractLinks(doc, "https://doruk.com")
 
 	if internal != 3 {
 		t.Errorf("Expected 3 internal links, got %d", internal)
@@ -184,7 +177,305 @@ func TestExtractLinks(t *testing.T) {
 	if external != 1 {
 		t.Errorf("Expected 1 external link, got %d", external)
 	}
-	if inaccessible != 3 {
-		t.Errorf("Expected 3 inaccessible links, got %d", inaccessible)
+}
+
+func TestCollectLinks(t *testing.T) {
+	htmlStr := `
+	<html>
+		<body>
+			<a href="/">Internal root</a>
+			<a href="page">Relative page</a>
+			<a href="/page#top">Internal page with fragment</a>
+			<a href="https://external.com">External</a>
+			<a href="https://external.com">Duplicate</a>
+			<a href="mailto:test@doruk.com">Email</a>
+			<a href="javascript:void(0)">Script</a>
+			<a href="#section">Fragment</a>
+			<a>No href</a>
+		</body>
+	</html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	result := CollectLinks(doc, "https://doruk.com/docs/index.html")
+
+	expected := []string{
+		"https://doruk.com/",
+		"https://doruk.com/docs/page",
+		"https://doruk.com/page",
+		"https://external.com/",
+	}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d links, got %d: %v", len(expected), len(result), result)
+	}
+
+	for i, link := range expected {
+		if result[i] != link {
+			t.Errorf("Expected link %d to be %q, got %q", i, link, result[i])
+		}
+	}
+}
+
+func TestCollectLinks_BaseElement(t *testing.T) {
+	htmlStr := `
+	<html>
+		<head><base href="/assets/v2/"></head>
+		<body>
+			<a href="../a">Up one level</a>
+			<a href="b?x=1#top">Relative</a>
+			<a href="/c">Absolute path</a>
+			<a href="HTTPS://Doruk.COM:443/d">Mixed case with default port</a>
+		</body>
+	</html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	result := CollectLinks(doc, "https://doruk.com/blog/post.html")
+
+	expected := []string{
+		"https://doruk.com/assets/a",
+		"https://doruk.com/assets/v2/b?x=1",
+		"https://doruk.com/c",
+		"https://doruk.com/d",
+	}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d links, got %d: %v", len(expected), len(result), result)
+	}
+	for i, link := range expected {
+		if result[i] != link {
+			t.Errorf("Expected link %d to be %q, got %q", i, link, result[i])
+		}
+	}
+
+	internal, external := ExtractLinks(doc, "https://doruk.com/blog/post.html")
+	if internal != 4 || external != 0 {
+		t.Errorf("Expected 4 internal and 0 external links, got %d and %d", internal, external)
+	}
+}
+
+func TestExtractLinks_CrossHostBase(t *testing.T) {
+	htmlStr := `
+	<html>
+		<head><base href="https://cdn.other.com/static/"></head>
+		<body>
+			<a href="page">Resolves on the CDN</a>
+			<a href="https://doruk.com/about">Back to the page's site</a>
+			<a href="https://blog.doruk.com/">Same site</a>
+		</body>
+	</html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	// Links resolve against <base href>, but the page decides what is internal
+	page, _ := url.Parse("https://doruk.com/")
+	links := resolveLinks(doc, documentBase(doc, page), page, Canonicalizer{}, ScopeDomain)
+
+	expected := []struct {
+		url      string
+		category models.LinkCategory
+	}{
+		{"https://cdn.other.com/static/page", models.CategoryExternal},
+		{"https://doruk.com/about", models.CategoryInternal},
+		{"https://blog.doruk.com/", models.CategoryInternal},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), links)
+	}
+	for i, want := range expected {
+		if links[i].URL != want.url || links[i].Category != want.category {
+			t.Errorf("Expected link %d to be %s %q, got %s %q", i, want.category, want.url, links[i].Category, links[i].URL)
+		}
+	}
+
+	internal, external := ExtractLinks(doc, "https://doruk.com/")
+	if internal != 2 || external != 1 {
+		t.Errorf("Expected 2 internal and 1 external links, got %d and %d", internal, external)
+	}
+}
+
+func TestResolveLinks_Categories(t *testing.T) {
+	htmlStr := `
+	<html>
+		<body>
+			<a href="//blog.doruk.com/post">Scheme-relative subdomain</a>
+			<a href="//evil.com/doruk.com">Scheme-relative external</a>
+			<a href="https://evil.com/?q=doruk.com">Domain in query</a>
+			<a href="https://doruk.com.evil.net/">Domain as subdomain</a>
+			<a href="mailto:test@doruk.com">Email</a>
+			<a href="tel:+905551234567">Phone</a>
+			<a href="JavaScript:void(0)">Script</a>
+			<a href="data:text/html,<h1>Hi</h1>">Data</a>
+			<a href="ftp://files.doruk.com/pub">FTP</a>
+			<a href="irc://irc.doruk.com/chat">Other</a>
+			<a href="#top">Fragment</a>
+		</body>
+	</html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	base, _ := url.Parse("https://doruk.com/")
+	internal, external, other := countLinks(resolveLinks(doc, base, base, Canonicalizer{}, ScopeDomain))
+
+	if internal != 1 {
+		t.Errorf("Expected 1 internal link, got %d", internal)
+	}
+	if external != 3 {
+		t.Errorf("Expected 3 external links, got %d", external)
+	}
+	expected := map[models.LinkCategory]int{
+		models.CategoryEmail:      1,
+		models.CategoryPhone:      1,
+		models.CategoryJavaScript: 1,
+		models.CategoryData:       1,
+		models.CategoryFTP:        1,
+		models.CategoryOther:      1,
+	}
+	if !maps.Equal(other, expected) {
+		t.Errorf("Expected other links %v, got %v", expected, other)
+	}
+
+	// Only http(s) links are checked
+	if urls := uniqueURLs(resolveLinks(doc, base, base, Canonicalizer{}, ScopeDomain)); len(urls) != 4 {
+		t.Errorf("Expected 4 links to check, got %v", urls)
+	}
+
+	internal, external, _ = countLinks(resolveLinks(doc, base, base, Canonicalizer{}, ScopeHost))
+	if internal != 0 || external != 4 {
+		t.Errorf("Expected 0 internal and 4 external links in host scope, got %d and %d", internal, external)
+	}
+}
+
+func TestResolveLinks_Details(t *testing.T) {
+	htmlStr := `
+	<html>
+		<body>
+			<nav>
+				<a href="/">Home</a>
+				<a href="/about" rel="NoFollow  noopener" target="_blank">
+					About <b>us</b>
+				</a>
+			</nav>
+			<a href="https://external.com"><img src="logo.png" alt="Partner"></a>
+		</body>
+	</html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	base, _ := url.Parse("https://doruk.com/")
+	links := resolveLinks(doc, base, base, Canonicalizer{}, ScopeDomain)
+
+	expected := []models.Link{
+		{Href: "/", URL: "https://doruk.com/", Text: "Home", Category: models.CategoryInternal, Resource: models.ResourcePage,
+			Element: "a", Attribute: "href", Position: 1, Path: "html > body > nav > a:nth-of-type(1)"},
+		{Href: "/about", URL: "https://doruk.com/about", Text: "About us", Rel: []string{"nofollow", "noopener"}, Target: "_blank",
+			Category: models.CategoryInternal, Resource: models.ResourcePage, Element: "a", Attribute: "href", Position: 2,
+			Path: "html > body > nav > a:nth-of-type(2)"},
+		{Href: "https://external.com", URL: "https://external.com/", Text: "Partner", Category: models.CategoryExternal,
+			Resource: models.ResourcePage, Element: "a", Attribute: "href", Position: 3, Path: "html > body > a"},
+		{Href: "logo.png", URL: "https://doruk.com/logo.png", Text: "Partner", Category: models.CategoryInternal,
+			Resource: models.ResourceImage, Element: "img", Attribute: "src", Position: 4, Path: "html > body > a > img"},
+	}
+
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links, got %d: %+v", len(expected), len(links), links)
+	}
+	for i, link := range links {
+		if !reflect.DeepEqual(link, expected[i]) {
+			t.Errorf("Link %d:\n got %+v\nwant %+v", i, link, expected[i])
+		}
+	}
+}
+
+func TestResolveLinks_Resources(t *testing.T) {
+	htmlStr := `
+	<html>
+		<head>
+			<link rel="stylesheet" href="/style.css">
+			<link rel="icon" href="/favicon.ico">
+			<link rel="preload" href="/font.woff2" as="font">
+			<link rel="canonical" href="https://doruk.com/">
+			<script src="http://cdn.doruk.com/app.js"></script>
+		</head>
+		<body style="background: url('/bg.png')">
+			<img src="/a.png" srcset="/a-1x.png 1x, /a-2x.png 2x" alt="A">
+			<map><area href="/region" alt="Region"></map>
+			<iframe src="https://video.external.com/embed"></iframe>
+			<picture><source srcset="/b.webp"></picture>
+			<video src="/clip.mp4" poster="/poster.jpg"><track src="/subs.vtt"></video>
+			<audio src="/sound.mp3"></audio>
+			<form action="http://doruk.com/login"></form>
+			<div style="background-image: URL(&quot;http://doruk.com/tile.png&quot;)"></div>
+		</body>
+	</html>`
+
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	base, _ := url.Parse("https://doruk.com/")
+	links := resolveLinks(doc, base, base, Canonicalizer{}, ScopeDomain)
+
+	expected := []struct {
+		url       string
+		resource  models.ResourceType
+		attribute string
+	}{
+		{"https://doruk.com/style.css", models.ResourceStylesheet, "href"},
+		{"https://doruk.com/favicon.ico", models.ResourceIcon, "href"},
+		{"https://doruk.com/font.woff2", models.ResourceFont, "href"},
+		{"https://doruk.com/", models.ResourceLink, "href"},
+		{"http://cdn.doruk.com/app.js", models.ResourceScript, "src"},
+		{"https://doruk.com/bg.png", models.ResourceStyle, "style"},
+		{"https://doruk.com/a.png", models.ResourceImage, "src"},
+		{"https://doruk.com/a-1x.png", models.ResourceImage, "srcset"},
+		{"https://doruk.com/a-2x.png", models.ResourceImage, "srcset"},
+		{"https://doruk.com/region", models.ResourcePage, "href"},
+		{"https://video.external.com/embed", models.ResourceFrame, "src"},
+		{"https://doruk.com/b.webp", models.ResourceImage, "srcset"},
+		{"https://doruk.com/clip.mp4", models.ResourceMedia, "src"},
+		{"https://doruk.com/poster.jpg", models.ResourceImage, "poster"},
+		{"https://doruk.com/subs.vtt", models.ResourceMedia, "src"},
+		{"https://doruk.com/sound.mp3", models.ResourceMedia, "src"},
+		{"http://doruk.com/login", models.ResourceForm, "action"},
+		{"http://doruk.com/tile.png", models.ResourceStyle, "style"},
+	}
+
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links, got %d: %+v", len(expected), len(links), links)
+	}
+	for i, link := range links {
+		if link.URL != expected[i].url || link.Resource != expected[i].resource || link.Attribute != expected[i].attribute {
+			t.Errorf("Link %d: got %s %s %s, want %s %s %s", i, link.URL, link.Resource, link.Attribute,
+				expected[i].url, expected[i].resource, expected[i].attribute)
+		}
+	}
+
+	if mixed := markMixedContent(links, base); mixed != 3 {
+		t.Errorf("Expected 3 mixed content links, got %d", mixed)
+	}
+
+	// Form actions are reported but not probed
+	for _, u := range uniqueURLs(links) {
+		if u == "http://doruk.com/login" {
+			t.Error("Expected the form action not to be checked")
+		}
 	}
 }
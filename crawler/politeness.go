@@ -0,0 +1,172 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLimiterHosts is how many hosts a limiter tracks before it forgets idle ones
+const maxLimiterHosts = 10000
+
+// HostLimiter spaces out requests to each host and caps how many run at once.
+// One limiter can be shared by many crawls, see Options.Limiter.
+// A nil *HostLimiter lets everything through.
+type HostLimiter struct {
+	interval time.Duration
+	maxConns int
+	// parent is the shared limiter a crawl's own limits are added to
+	parent *HostLimiter
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	conns chan struct{}
+	// next is the earliest time the next request may start
+	next       time.Time
+	crawlDelay time.Duration
+	// waiters counts the requests between looking up the state and reserving a
+	// start time, forgetIdle keeps the state for them
+	waiters int
+}
+
+// NewHostLimiter limits requests per host with the rate, concurrency and delay of
+// opts. Crawls that share it take turns, however many of them run at once.
+func NewHostLimiter(opts Options) *HostLimiter {
+	return newHostLimiter(opts.withDefaults())
+}
+
+func newHostLimiter(opts Options) *HostLimiter {
+	interval := opts.MinHostDelay
+	if opts.HostRequestsPerSecond > 0 {
+		interval = max(interval, time.Duration(float64(time.Second)/opts.HostRequestsPerSecond))
+	}
+
+	return &HostLimiter{
+		interval: interval,
+		maxConns: opts.MaxHostConnections,
+		hosts:    make(map[string]*hostState),
+	}
+}
+
+func (l *HostLimiter) state(host string) *hostState {
+	host = strings.ToLower(host)
+	st, ok := l.hosts[host]
+	if !ok {
+		if len(l.hosts) >= maxLimiterHosts {
+			l.forgetIdle()
+		}
+		st = &hostState{}
+		if l.maxConns > 0 {
+			st.conns = make(chan struct{}, l.maxConns)
+		}
+		l.hosts[host] = st
+	}
+	return st
+}
+
+// forgetIdle drops the hosts nobody is waiting for. Their crawl delay is set
+// again the next time robots.txt is checked.
+func (l *HostLimiter) forgetIdle() {
+	now := time.Now()
+	for host, st := range l.hosts {
+		if st.next.Before(now) && len(st.conns) == 0 && st.waiters == 0 {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// withParent returns a limiter with the limits of l that also waits for parent
+func (l *HostLimiter) withParent(parent *HostLimiter) *HostLimiter {
+	l.parent = parent
+	return l
+}
+
+// setCrawlDelay makes the robots.txt Crawl-delay of host part of its interval
+func (l *HostLimiter) setCrawlDelay(host string, delay time.Duration) {
+	if l == nil {
+		return
+	}
+	l.parent.setCrawlDelay(host, delay)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.state(host).crawlDelay = delay
+}
+
+// acquire waits for a free connection slot and the next start time on host,
+// first in l and then in its parent. The returned release must be called once
+// the response is done with.
+func (l *HostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release, err := l.acquireOwn(ctx, host)
+	if err != nil || l.parent == nil {
+		return release, err
+	}
+
+	releaseParent, err := l.parent.acquire(ctx, host)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return func() {
+		releaseParent()
+		release()
+	}, nil
+}
+
+func (l *HostLimiter) acquireOwn(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	st := l.state(host)
+	st.waiters++
+	l.mu.Unlock()
+
+	release := func() {}
+	if st.conns != nil {
+		select {
+		case st.conns <- struct{}{}:
+		case <-ctx.Done():
+			l.mu.Lock()
+			st.waiters--
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		release = sync.OnceFunc(func() { <-st.conns })
+	}
+
+	// Reserve a start time so waiting requests leave in order, one interval apart
+	l.mu.Lock()
+	now := time.Now()
+	start := st.next
+	if start.Before(now) {
+		start = now
+	}
+	st.next = start.Add(max(l.interval, st.crawlDelay))
+	st.waiters--
+	l.mu.Unlock()
+
+	if err := sleep(ctx, time.Until(start)); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// releaseBody frees the connection slot of a response once its body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,174 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_Interval(t *testing.T) {
+	limiter := newHostLimiter(Options{HostRequestsPerSecond: 20, MaxHostConnections: -1})
+
+	started := time.Now()
+	for range 5 {
+		release, err := limiter.acquire(context.Background(), "example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	// The first request goes right away, the next four wait 50ms each
+	if elapsed := time.Since(started); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected 5 requests at 20 rps to take at least 200ms, took %v", elapsed)
+	}
+
+	// Other hosts have their own schedule
+	started = time.Now()
+	release, _ := limiter.acquire(context.Background(), "other.example.com")
+	release()
+	if elapsed := time.Since(started); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected another host not to wait, took %v", elapsed)
+	}
+}
+
+func TestHostLimiter_CrawlDelay(t *testing.T) {
+	limiter := newHostLimiter(Options{MinHostDelay: 10 * time.Millisecond, MaxHostConnections: -1})
+	limiter.setCrawlDelay("example.com", 100*time.Millisecond)
+
+	started := time.Now()
+	for range 2 {
+		release, _ := limiter.acquire(context.Background(), "example.com")
+		release()
+	}
+
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected the longer crawl delay to win over the minimum delay, took %v", elapsed)
+	}
+}
+
+func TestHostLimiter_Shared(t *testing.T) {
+	shared := newHostLimiter(Options{HostRequestsPerSecond: 20, MaxHostConnections: -1})
+	// Both crawls ask for no limits at all, the shared limiter still spaces them out
+	crawls := []*HostLimiter{
+		newHostLimiter(Options{HostRequestsPerSecond: -1, MaxHostConnections: -1}).withParent(shared),
+		newHostLimiter(Options{HostRequestsPerSecond: -1, MaxHostConnections: -1}).withParent(shared),
+	}
+
+	started := time.Now()
+	for i := range 5 {
+		release, err := crawls[i%2].acquire(context.Background(), "example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(started); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected 5 requests of two crawls at a shared 20 rps to take at least 200ms, took %v", elapsed)
+	}
+
+	// A crawl can still be stricter than the shared limits
+	strict := newHostLimiter(Options{HostRequestsPerSecond: 10, MaxHostConnections: -1}).withParent(shared)
+	started = time.Now()
+	for range 3 {
+		release, _ := strict.acquire(context.Background(), "strict.example.com")
+		release()
+	}
+	if elapsed := time.Since(started); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected 3 requests at 10 rps to take at least 200ms, took %v", elapsed)
+	}
+}
+
+func TestHostLimiter_Cancelled(t *testing.T) {
+	limiter := newHostLimiter(Options{MaxHostConnections: 1})
+
+	release, _ := limiter.acquire(context.Background(), "example.com")
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.acquire(ctx, "example.com"); err == nil {
+		t.Error("Expected acquire to fail once the context is done")
+	}
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if n := limiter.hosts["example.com"].waiters; n != 0 {
+		t.Errorf("Expected the cancelled request to stop waiting, got %d waiters", n)
+	}
+}
+
+func TestHostLimiter_ForgetIdle(t *testing.T) {
+	limiter := newHostLimiter(Options{MaxHostConnections: 1})
+
+	release, _ := limiter.acquire(context.Background(), "busy.example.com")
+	defer release()
+
+	limiter.mu.Lock()
+	limiter.state("idle.example.com")
+	// A request that looked up its state but hasn't taken a slot yet
+	limiter.state("waiting.example.com").waiters++
+	limiter.forgetIdle()
+	_, idle := limiter.hosts["idle.example.com"]
+	_, busy := limiter.hosts["busy.example.com"]
+	_, waiting := limiter.hosts["waiting.example.com"]
+	limiter.mu.Unlock()
+
+	if idle {
+		t.Error("Expected the idle host to be forgotten")
+	}
+	if !busy {
+		t.Error("Expected the host with a request in flight to be kept")
+	}
+	if !waiting {
+		t.Error("Expected the host with a waiting request to be kept")
+	}
+}
+
+func TestCrawlURL_HostConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+
+		if r.URL.Path == "/" {
+			var links strings.Builder
+			for i := range 8 {
+				fmt.Fprintf(&links, `<a href="/page-%d">Page</a>`, i)
+			}
+			fmt.Fprintf(w, `<html><head><title>Links</title></head><body>%s</body></html>`, links.String())
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	opts := Options{IgnoreRobots: true, HostRequestsPerSecond: -1, MaxHostConnections: 2}
+	result := CrawlURLWithOptions(context.Background(), server.URL, opts)
+
+	if !result.Success {
+		t.Fatalf("Expected successful crawl, got error: %s", result.Error)
+	}
+	if len(result.Links) != 8 || result.InaccessibleLinks != 0 {
+		t.Errorf("Expected 8 accessible links, got %d links and %d inaccessible", len(result.Links), result.InaccessibleLinks)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", peak)
+	}
+}
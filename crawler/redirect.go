@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"go-webcrawler/models"
+	"net/http"
+	"net/url"
+)
+
+// fetch requests result.URL and follows redirects itself so every hop ends up in
+// result.Redirects. It returns nil after setting result.Error when there is no
+// response to analyze.
+func (s *session) fetch(ctx context.Context, result *models.CrawlResult) *http.Response {
+	current := result.URL
+	visited := make(map[string]bool)
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, "GET", current, nil)
+		if err != nil {
+			result.Error = fmt.Sprintf("Failed to create request: %v", err)
+			return nil
+		}
+
+		if !s.allowed(ctx, req.URL, result) {
+			return nil
+		}
+
+		resp, err := s.do(ctx, req, result)
+		if err != nil {
+			if ctx.Err() != nil {
+				result.Error = "Crawl cancelled"
+			} else {
+				result.Error = fmt.Sprintf("Network error: %v", err)
+			}
+			return nil
+		}
+
+		result.FinalURL = current
+		result.StatusCode = resp.StatusCode
+		result.Status = resp.Status
+
+		location := resp.Header.Get("Location")
+		if !isRedirect(resp.StatusCode) || location == "" || s.opts.MaxRedirects < 0 {
+			return resp
+		}
+		resp.Body.Close()
+
+		next, err := req.URL.Parse(location)
+		if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+			result.Error = fmt.Sprintf("Redirect to unsupported location %q", location)
+			return nil
+		}
+		next.Fragment = ""
+
+		result.Redirects = append(result.Redirects, models.Redirect{
+			URL:        current,
+			StatusCode: resp.StatusCode,
+			Location:   next.String(),
+		})
+		if req.URL.Scheme == "https" && next.Scheme == "http" {
+			result.InsecureRedirect = true
+		}
+
+		visited[current] = true
+		if visited[next.String()] {
+			result.RedirectLoop = true
+			result.Error = "Redirect loop detected"
+			return nil
+		}
+		if len(result.Redirects) > s.opts.MaxRedirects {
+			result.Error = fmt.Sprintf("Too many redirects, stopped after %d", s.opts.MaxRedirects)
+			return nil
+		}
+
+		current = next.String()
+	}
+}
+
+// allowed checks robots.txt and waits for the crawl delay of u's host
+func (s *session) allowed(ctx context.Context, u *url.URL, result *models.CrawlResult) bool {
+	if s.opts.IgnoreRobots {
+		return true
+	}
+
+	robots := s.robots.get(ctx, u)
+	agent := s.opts.robotsAgent()
+	if !robots.Allowed(agent, robotsPath(u)) {
+		result.BlockedByRobots = true
+		result.Error = "Blocked by robots.txt"
+		return false
+	}
+	s.limiter.setCrawlDelay(u.Host, robots.CrawlDelay(agent))
+	return true
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newRedirectServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/docs/final", http.StatusFound)
+		case "/docs/final":
+			fmt.Fprint(w, `<html><head><title>Final</title></head><body><a href="next">Next</a></body></html>`)
+		case "/docs/next":
+			w.WriteHeader(http.StatusOK)
+		case "/loop-a":
+			http.Redirect(w, r, "/loop-b", http.StatusTemporaryRedirect)
+		case "/loop-b":
+			http.Redirect(w, r, "/loop-a", http.StatusTemporaryRedirect)
+		case "/mailto":
+			http.Redirect(w, r, "mailto:someone@example.com", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCrawlURL_RedirectChain(t *testing.T) {
+	server := newRedirectServer()
+	defer server.Close()
+
+	result := CrawlURLWithOptions(context.Background(), server.URL+"/start", Options{IgnoreRobots: true})
+
+	if !result.Success {
+		t.Fatalf("Expected successful crawl, got error: %s", result.Error)
+	}
+	if result.URL != server.URL+"/start" {
+		t.Errorf("Expected URL to stay the requested one, got %q", result.URL)
+	}
+	if result.FinalURL != server.URL+"/docs/final" {
+		t.Errorf("Expected final URL %q, got %q", server.URL+"/docs/final", result.FinalURL)
+	}
+
+	if len(result.Redirects) != 2 {
+		t.Fatalf("Expected 2 redirects, got %+v", result.Redirects)
+	}
+	first := result.Redirects[0]
+	if first.URL != server.URL+"/start" || first.StatusCode != 301 || first.Location != server.URL+"/moved" {
+		t.Errorf("Unexpected first hop %+v", first)
+	}
+	if second := result.Redirects[1]; second.StatusCode != 302 || second.Location != server.URL+"/docs/final" {
+		t.Errorf("Unexpected second hop %+v", second)
+	}
+
+	// Relative links resolve against the final URL, not the requested one
+	if len(result.Links) != 1 || result.Links[0].URL != server.URL+"/docs/next" {
+		t.Errorf("Expected link resolved against the final URL, got %+v", result.Links)
+	}
+}
+
+func TestCrawlURL_RedirectErrors(t *testing.T) {
+	server := newRedirectServer()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		opts      Options
+		redirects int
+		loop      bool
+	}{
+		{"Loop", "/loop-a", Options{}, 2, true},
+		{"Too many", "/start", Options{MaxRedirects: 1}, 2, false},
+		{"Not followed", "/start", Options{MaxRedirects: -1}, 0, false},
+		{"Unsupported scheme", "/mailto", Options{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.IgnoreRobots = true
+			result := CrawlURLWithOptions(context.Background(), server.URL+tt.path, tt.opts)
+
+			if result.Success {
+				t.Error("Expected crawl to fail")
+			}
+			if result.Error == "" {
+				t.Error("Expected an error message")
+			}
+			if len(result.Redirects) != tt.redirects {
+				t.Errorf("Expected %d redirects, got %+v", tt.redirects, result.Redirects)
+			}
+			if result.RedirectLoop != tt.loop {
+				t.Errorf("Expected redirect loop %v, got %v", tt.loop, result.RedirectLoop)
+			}
+		})
+	}
+}
+
+func TestCrawlURL_InsecureRedirect(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Plain</title></head></html>`)
+	}))
+	defer plain.Close()
+
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, plain.URL, http.StatusMovedPermanently)
+	}))
+	defer secure.Close()
+
+	result := CrawlURLWithOptions(context.Background(), secure.URL, Options{IgnoreRobots: true, InsecureSkipVerify: true})
+
+	if !result.Success {
+		t.Fatalf("Expected successful crawl, got error: %s", result.Error)
+	}
+	if !result.InsecureRedirect {
+		t.Error("Expected the https to http redirect to be flagged")
+	}
+}
@@ -0,0 +1,356 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// renderIdleConnections is how many requests may still be open when the
+// network counts as idle, so long polling and analytics beacons don't stall
+// rendering until the timeout
+const renderIdleConnections = 2
+
+// renderPoll is how often the wait selector is checked
+const renderPoll = 100 * time.Millisecond
+
+// serializeDOM returns the doctype and the DOM as HTML
+const serializeDOM = `(document.doctype ? new XMLSerializer().serializeToString(document.doctype) : "") + document.documentElement.outerHTML`
+
+// renderer loads pages in a headless Chrome over the Chrome DevTools
+// Protocol. Every page gets its own tab, which is closed afterwards.
+type renderer struct {
+	endpoint  string
+	client    *http.Client
+	userAgent string
+	headers   map[string]string
+	selector  string
+	idle      time.Duration
+	timeout   time.Duration
+}
+
+func newRenderer(opts Options) *renderer {
+	return &renderer{
+		endpoint:  strings.TrimSuffix(opts.ChromeURL, "/"),
+		client:    &http.Client{Timeout: opts.ConnectTimeout},
+		userAgent: opts.UserAgent,
+		headers:   opts.Headers,
+		selector:  opts.RenderWaitSelector,
+		idle:      opts.RenderIdle,
+		timeout:   opts.Timeout,
+	}
+}
+
+// target is a Chrome tab as listed by the /json endpoints
+type target struct {
+	ID                   string `json:"id"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+// render loads pageURL, waits for the network to go idle or the wait selector
+// to match and returns the serialized DOM
+func (r *renderer) render(ctx context.Context, pageURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tab, err := r.newTarget(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to open a Chrome tab at %s: %v", r.endpoint, err)
+	}
+	defer r.closeTarget(tab)
+
+	config, err := websocket.NewConfig(tab.WebSocketDebuggerURL, r.endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid DevTools WebSocket URL %q: %v", tab.WebSocketDebuggerURL, err)
+	}
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to Chrome, is it started with --remote-allow-origins=%s? %v", r.endpoint, err)
+	}
+	conn := newCDPConn(ws)
+	defer conn.close()
+
+	if err := r.navigate(ctx, conn, pageURL); err != nil {
+		return "", err
+	}
+
+	if r.selector != "" {
+		err = r.waitForSelector(ctx, conn)
+	} else {
+		err = conn.waitIdle(ctx, r.idle)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var rendered string
+	if err := conn.evaluate(ctx, serializeDOM, &rendered); err != nil {
+		return "", fmt.Errorf("failed to read the rendered DOM: %v", err)
+	}
+	return rendered, nil
+}
+
+func (r *renderer) navigate(ctx context.Context, conn *cdpConn, pageURL string) error {
+	for _, method := range []string{"Page.enable", "Network.enable"} {
+		if err := conn.call(ctx, method, nil, nil); err != nil {
+			return fmt.Errorf("%s failed: %v", method, err)
+		}
+	}
+
+	if err := conn.call(ctx, "Network.setUserAgentOverride", map[string]string{"userAgent": r.userAgent}, nil); err != nil {
+		return fmt.Errorf("failed to set the User-Agent: %v", err)
+	}
+	if len(r.headers) > 0 {
+		if err := conn.call(ctx, "Network.setExtraHTTPHeaders", map[string]any{"headers": r.headers}, nil); err != nil {
+			return fmt.Errorf("failed to set headers: %v", err)
+		}
+	}
+
+	var navigation struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := conn.call(ctx, "Page.navigate", map[string]string{"url": pageURL}, &navigation); err != nil {
+		return fmt.Errorf("navigation failed: %v", err)
+	}
+	if navigation.ErrorText != "" {
+		return fmt.Errorf("navigation failed: %s", navigation.ErrorText)
+	}
+	return nil
+}
+
+func (r *renderer) waitForSelector(ctx context.Context, conn *cdpConn) error {
+	selector, _ := json.Marshal(r.selector)
+	expression := fmt.Sprintf("document.querySelector(%s) !== null", selector)
+
+	for {
+		var found bool
+		if err := conn.evaluate(ctx, expression, &found); err != nil {
+			return fmt.Errorf("failed to check selector %q: %v", r.selector, err)
+		}
+		if found {
+			return nil
+		}
+		if err := conn.drain(ctx, renderPoll); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("selector %q did not match before the timeout", r.selector)
+			}
+			return err
+		}
+	}
+}
+
+func (r *renderer) newTarget(ctx context.Context) (target, error) {
+	// Chrome only accepts PUT for /json/new since version 111
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.endpoint+"/json/new?about:blank", nil)
+	if err != nil {
+		return target{}, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return target{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return target{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var tab target
+	if err := json.NewDecoder(resp.Body).Decode(&tab); err != nil {
+		return target{}, err
+	}
+	if tab.WebSocketDebuggerURL == "" {
+		return target{}, errors.New("no WebSocket URL for the new tab")
+	}
+	return tab, nil
+}
+
+func (r *renderer) closeTarget(tab target) {
+	resp, err := r.client.Get(r.endpoint + "/json/close/" + url.PathEscape(tab.ID))
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+// cdpMessage is a command response or an event
+type cdpMessage struct {
+	ID     int             `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// cdpConn is a DevTools connection to one tab. It keeps track of the
+// requests in flight while it waits for command responses.
+type cdpConn struct {
+	ws       *websocket.Conn
+	nextID   int
+	messages chan cdpMessage
+	done     chan struct{}
+	// err is why messages was closed
+	err error
+
+	loaded   bool
+	inflight map[string]bool
+}
+
+func newCDPConn(ws *websocket.Conn) *cdpConn {
+	c := &cdpConn{
+		ws:       ws,
+		messages: make(chan cdpMessage),
+		done:     make(chan struct{}),
+		inflight: make(map[string]bool),
+	}
+	go c.read()
+	return c
+}
+
+func (c *cdpConn) read() {
+	defer close(c.messages)
+	for {
+		var msg cdpMessage
+		if err := websocket.JSON.Receive(c.ws, &msg); err != nil {
+			c.err = err
+			return
+		}
+		select {
+		case c.messages <- msg:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *cdpConn) close() {
+	close(c.done)
+	c.ws.Close()
+}
+
+// next returns the next message, or nil when nothing arrived within wait
+func (c *cdpConn) next(ctx context.Context, wait <-chan time.Time) (*cdpMessage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-wait:
+		return nil, nil
+	case msg, ok := <-c.messages:
+		if !ok {
+			return nil, fmt.Errorf("connection to Chrome lost: %v", c.err)
+		}
+		c.handle(msg)
+		return &msg, nil
+	}
+}
+
+// handle tracks page load and network events
+func (c *cdpConn) handle(msg cdpMessage) {
+	var params struct {
+		RequestID string `json:"requestId"`
+	}
+
+	switch msg.Method {
+	case "Page.loadEventFired":
+		c.loaded = true
+	case "Network.requestWillBeSent":
+		if json.Unmarshal(msg.Params, &params) == nil {
+			c.inflight[params.RequestID] = true
+		}
+	case "Network.loadingFinished", "Network.loadingFailed":
+		if json.Unmarshal(msg.Params, &params) == nil {
+			delete(c.inflight, params.RequestID)
+		}
+	}
+}
+
+// call sends a command and waits for its response, decoding the result into result when it is not nil
+func (c *cdpConn) call(ctx context.Context, method string, params any, result any) error {
+	c.nextID++
+	id := c.nextID
+
+	command := map[string]any{"id": id, "method": method}
+	if params != nil {
+		command["params"] = params
+	}
+	if err := websocket.JSON.Send(c.ws, command); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := c.next(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if msg.ID != id {
+			continue
+		}
+		if msg.Error != nil {
+			return errors.New(msg.Error.Message)
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	}
+}
+
+// evaluate runs a JavaScript expression in the page and decodes its value into value
+func (c *cdpConn) evaluate(ctx context.Context, expression string, value any) error {
+	var evaluation struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+	params := map[string]any{"expression": expression, "returnByValue": true}
+	if err := c.call(ctx, "Runtime.evaluate", params, &evaluation); err != nil {
+		return err
+	}
+	if evaluation.ExceptionDetails != nil {
+		return errors.New(evaluation.ExceptionDetails.Text)
+	}
+	return json.Unmarshal(evaluation.Result.Value, value)
+}
+
+// waitIdle returns once the page has loaded and no more than
+// renderIdleConnections requests were open for quiet
+func (c *cdpConn) waitIdle(ctx context.Context, quiet time.Duration) error {
+	for {
+		var timer <-chan time.Time
+		if c.loaded && len(c.inflight) <= renderIdleConnections {
+			timer = time.After(quiet)
+		}
+
+		msg, err := c.next(ctx, timer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return errors.New("the network did not go idle before the timeout")
+			}
+			return err
+		}
+		if msg == nil {
+			return nil
+		}
+	}
+}
+
+// drain handles events for wait
+func (c *cdpConn) drain(ctx context.Context, wait time.Duration) error {
+	timer := time.After(wait)
+	for {
+		msg, err := c.next(ctx, timer)
+		if err != nil || msg == nil {
+			return err
+		}
+	}
+}
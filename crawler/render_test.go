@@ -0,0 +1,179 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// fakeChrome speaks just enough of the DevTools protocol to render one page
+type fakeChrome struct {
+	*httptest.Server
+	html string
+
+	mu         sync.Mutex
+	navigated  string
+	userAgent  string
+	selectorOK int
+	closed     bool
+}
+
+func newFakeChrome(t *testing.T, rendered string) *fakeChrome {
+	chrome := &fakeChrome{html: rendered}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /json/new", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(target{
+			ID:                   "tab1",
+			WebSocketDebuggerURL: "ws://" + r.Host + "/devtools/page/tab1",
+		})
+	})
+	mux.HandleFunc("GET /json/close/tab1", func(w http.ResponseWriter, r *http.Request) {
+		chrome.mu.Lock()
+		chrome.closed = true
+		chrome.mu.Unlock()
+	})
+	mux.Handle("/devtools/page/tab1", websocket.Handler(chrome.serve))
+
+	chrome.Server = httptest.NewServer(mux)
+	t.Cleanup(chrome.Close)
+	return chrome
+}
+
+func (c *fakeChrome) serve(ws *websocket.Conn) {
+	for {
+		var command struct {
+			ID     int             `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := websocket.JSON.Receive(ws, &command); err != nil {
+			return
+		}
+
+		var params map[string]any
+		json.Unmarshal(command.Params, &params)
+		reply := map[string]any{"id": command.ID, "result": map[string]any{}}
+
+		switch command.Method {
+		case "Network.setUserAgentOverride":
+			c.mu.Lock()
+			c.userAgent, _ = params["userAgent"].(string)
+			c.mu.Unlock()
+		case "Page.navigate":
+			c.mu.Lock()
+			c.navigated, _ = params["url"].(string)
+			c.mu.Unlock()
+			websocket.JSON.Send(ws, reply)
+			for _, event := range []string{
+				`{"method": "Network.requestWillBeSent", "params": {"requestId": "1"}}`,
+				`{"method": "Network.requestWillBeSent", "params": {"requestId": "2"}}`,
+				`{"method": "Page.loadEventFired", "params": {}}`,
+				`{"method": "Network.loadingFinished", "params": {"requestId": "1"}}`,
+				`{"method": "Network.loadingFailed", "params": {"requestId": "2"}}`,
+			} {
+				websocket.Message.Send(ws, event)
+			}
+			continue
+		case "Runtime.evaluate":
+			expression, _ := params["expression"].(string)
+			var value any = c.html
+			if strings.Contains(expression, "querySelector") {
+				// The selector matches on the second check
+				c.mu.Lock()
+				c.selectorOK++
+				value = c.selectorOK > 1
+				c.mu.Unlock()
+			}
+			reply["result"] = map[string]any{"result": map[string]any{"value": value}}
+		}
+		websocket.JSON.Send(ws, reply)
+	}
+}
+
+func TestCrawlURL_Render(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Loading</title></head><body><div id="app"></div></body></html>`)
+	}))
+	defer server.Close()
+
+	rendered := `<!DOCTYPE html><html><head><title>App</title></head><body><div id="app">` +
+		`<h1>Dashboard</h1><a href="/settings">Settings</a></div></body></html>`
+
+	tests := []struct {
+		name     string
+		selector string
+	}{
+		{"Network idle", ""},
+		{"Wait selector", "#app h1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chrome := newFakeChrome(t, rendered)
+			opts := Options{
+				UserAgent:             "render-test/1.0",
+				Render:                true,
+				ChromeURL:             chrome.URL,
+				RenderWaitSelector:    tt.selector,
+				HostRequestsPerSecond: -1,
+			}
+
+			result := CrawlURLWithOptions(context.Background(), server.URL, opts)
+
+			if !result.Rendered {
+				t.Fatalf("Expected a rendered result, got error %q", result.RenderError)
+			}
+			if result.Title != "App" {
+				t.Errorf("Expected the rendered title, got %q", result.Title)
+			}
+			if result.Headings["h1"] != 1 || result.InternalLinks != 1 {
+				t.Errorf("Expected the rendered heading and link, got %v and %d links", result.Headings, result.InternalLinks)
+			}
+			if result.HTMLVersion != "HTML5" {
+				t.Errorf("Expected the doctype to survive rendering, got %q", result.HTMLVersion)
+			}
+
+			chrome.mu.Lock()
+			defer chrome.mu.Unlock()
+			if chrome.navigated != server.URL+"/" {
+				t.Errorf("Expected Chrome to load %q, got %q", server.URL+"/", chrome.navigated)
+			}
+			if chrome.userAgent != opts.UserAgent {
+				t.Errorf("Expected User-Agent %q in Chrome, got %q", opts.UserAgent, chrome.userAgent)
+			}
+			if !chrome.closed {
+				t.Error("Expected the tab to be closed")
+			}
+		})
+	}
+}
+
+func TestCrawlURL_RenderFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Static</title></head></html>`)
+	}))
+	defer server.Close()
+
+	chrome := httptest.NewServer(http.NotFoundHandler())
+	defer chrome.Close()
+
+	result := CrawlURLWithOptions(context.Background(), server.URL, Options{Render: true, ChromeURL: chrome.URL})
+
+	if result.Rendered {
+		t.Error("Expected the static HTML to be used")
+	}
+	if result.RenderError == "" {
+		t.Error("Expected the render error to be recorded")
+	}
+	if !result.Success || result.Title != "Static" {
+		t.Errorf("Expected the static page to be analyzed, got success %v and title %q", result.Success, result.Title)
+	}
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"context"
+	"go-webcrawler/models"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// maxDrainSize is how much of a discarded body is read so the connection can be reused
+const maxDrainSize = 64 * 1024
+
+// do sends req and retries it on the errors and statuses of the retry policy,
+// recording every attempt in result.Attempts
+func (s *session) do(ctx context.Context, req *http.Request, result *models.CrawlResult) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		release, err := s.limiter.acquire(ctx, req.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+
+		started := time.Now()
+		resp, err := s.client.Do(req.Clone(ctx))
+		if err != nil {
+			release()
+		} else {
+			resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+		}
+
+		record := models.Attempt{
+			URL:        req.URL.String(),
+			DurationMS: time.Since(started).Milliseconds(),
+		}
+		if err != nil {
+			record.Error = err.Error()
+		} else {
+			record.StatusCode = resp.StatusCode
+		}
+
+		wait, retry := s.retryDelay(ctx, attempt, resp, err)
+		if retry {
+			record.WaitMS = wait.Milliseconds()
+		}
+		result.Attempts = append(result.Attempts, record)
+
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+			resp.Body.Close()
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay decides whether another attempt is worth it and how long to wait for it
+func (s *session) retryDelay(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= s.opts.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return s.backoff(attempt), slices.Contains(s.opts.RetryErrors, classifyLinkError(err))
+	}
+
+	if !s.opts.RetryStatuses.Contains(resp.StatusCode) {
+		return 0, false
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if delay, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			// Waiting longer than we are willing to is no better than failing now
+			return delay, delay <= s.opts.RetryMaxDelay
+		}
+	}
+
+	return s.backoff(attempt), true
+}
+
+// backoff doubles the base delay with every attempt and picks a random point in
+// its upper half, so crawlers that failed together don't retry together
+func (s *session) backoff(attempt int) time.Duration {
+	delay := s.opts.RetryBaseDelay
+	for i := 1; i < attempt && delay < s.opts.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, s.opts.RetryMaxDelay)
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
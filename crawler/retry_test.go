@@ -0,0 +1,154 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawlURL_Retry(t *testing.T) {
+	var flaky, limited, broken atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if flaky.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/limited":
+			if limited.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/limited-long":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case "/broken":
+			broken.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>Retried</title></head></html>`)
+	}))
+	defer server.Close()
+
+	fast := Options{IgnoreRobots: true, HostRequestsPerSecond: -1, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 10 * time.Millisecond}
+	// A huge base delay proves that Retry-After is used instead of the backoff
+	slow := Options{IgnoreRobots: true, RetryBaseDelay: time.Minute, RetryMaxDelay: time.Hour / 2}
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     Options
+		success  bool
+		attempts int
+	}{
+		{"Succeeds on third attempt", "/flaky", fast, true, 3},
+		{"Honors Retry-After", "/limited", slow, true, 2},
+		{"Retry-After too long", "/limited-long", slow, false, 1},
+		{"Gives up", "/broken", fast, false, DefaultMaxAttempts},
+		{"Not retryable", "/missing", fast, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CrawlURLWithOptions(context.Background(), server.URL+tt.path, tt.opts)
+
+			if result.Success != tt.success {
+				t.Errorf("Expected success %v, got %v (error: %s)", tt.success, result.Success, result.Error)
+			}
+			if len(result.Attempts) != tt.attempts {
+				t.Fatalf("Expected %d attempts, got %+v", tt.attempts, result.Attempts)
+			}
+
+			last := result.Attempts[len(result.Attempts)-1]
+			if last.WaitMS != 0 {
+				t.Errorf("Expected no wait after the last attempt, got %dms", last.WaitMS)
+			}
+			if last.StatusCode != result.StatusCode {
+				t.Errorf("Expected last attempt status %d, got %d", result.StatusCode, last.StatusCode)
+			}
+		})
+	}
+
+	if broken.Load() != DefaultMaxAttempts {
+		t.Errorf("Expected %d requests to /broken, got %d", DefaultMaxAttempts, broken.Load())
+	}
+}
+
+func TestCrawlURL_RetryNetworkError(t *testing.T) {
+	// A closed listener gives an address that refuses connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	opts := Options{IgnoreRobots: true, MaxAttempts: 2, RetryBaseDelay: time.Millisecond}
+	result := CrawlURLWithOptions(context.Background(), "http://"+addr, opts)
+
+	if result.Success {
+		t.Error("Expected crawl of a closed port to fail")
+	}
+	if len(result.Attempts) != 2 {
+		t.Fatalf("Expected 2 attempts, got %+v", result.Attempts)
+	}
+	for _, attempt := range result.Attempts {
+		if attempt.Error == "" {
+			t.Errorf("Expected every attempt to record the error, got %+v", attempt)
+		}
+	}
+}
+
+func TestCrawlURL_RetryCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	result := CrawlURLWithOptions(ctx, server.URL, Options{IgnoreRobots: true, RetryBaseDelay: time.Minute, RetryMaxDelay: time.Minute})
+
+	if time.Since(started) > 5*time.Second {
+		t.Error("Expected cancellation to stop the backoff")
+	}
+	if result.Error != "Crawl cancelled" {
+		t.Errorf("Expected cancelled crawl, got %q", result.Error)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	s := &session{opts: Options{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second}}
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			if d := s.backoff(tt.attempt); d < tt.min || d > tt.max {
+				t.Errorf("backoff(%d) = %v; want between %v and %v", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RobotsUserAgent = "go-webcrawler"
+	MaxCrawlDelay   = 10 * time.Second
+	maxRobotsSize   = 500 * 1024
+)
+
+type Robots struct {
+	groups []robotsGroup
+	// disallowAll is set when robots.txt could not be fetched because of a server error
+	disallowAll bool
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+func ParseRobots(r io.Reader) *Robots {
+	robots := &Robots{}
+	var current *robotsGroup
+	// Consecutive user-agent lines share one group
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				robots.groups = append(robots.groups, robotsGroup{})
+				current = &robots.groups[len(robots.groups)-1]
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+		case "allow", "disallow":
+			lastWasAgent = false
+			// An empty Disallow allows everything, which is the default anyway
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{pattern: value, allow: key == "allow"})
+		case "crawl-delay":
+			lastWasAgent = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		default:
+			lastWasAgent = false
+		}
+	}
+
+	return robots
+}
+
+func (r *Robots) Allowed(userAgent, path string) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	// The longest matching rule wins, Allow wins a tie
+	best := -1
+	allowed := true
+	for _, rule := range r.rulesFor(userAgent) {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+		if length := len(rule.pattern); length > best || (length == best && rule.allow) {
+			best = length
+			allowed = rule.allow
+		}
+	}
+
+	return allowed
+}
+
+func (r *Robots) CrawlDelay(userAgent string) time.Duration {
+	delay := time.Duration(0)
+	for _, group := range r.groupsFor(userAgent) {
+		delay = max(delay, group.crawlDelay)
+	}
+	return min(delay, MaxCrawlDelay)
+}
+
+func (r *Robots) rulesFor(userAgent string) []robotsRule {
+	var rules []robotsRule
+	for _, group := range r.groupsFor(userAgent) {
+		rules = append(rules, group.rules...)
+	}
+	return rules
+}
+
+// groupsFor returns the groups whose user-agent is the product token of userAgent,
+// falling back to the "*" groups. Tokens are compared ignoring case, as RFC 9309 asks.
+func (r *Robots) groupsFor(userAgent string) []robotsGroup {
+	token := productToken(userAgent)
+
+	var matched []robotsGroup
+	var wildcard []robotsGroup
+
+	for _, group := range r.groups {
+		for _, agent := range group.agents {
+			if agent == "*" {
+				wildcard = append(wildcard, group)
+				break
+			}
+			if agent != "" && agent == token {
+				matched = append(matched, group)
+				break
+			}
+		}
+	}
+
+	if len(matched) > 0 {
+		return matched
+	}
+	return wildcard
+}
+
+// robotsAgent is the user agent robots.txt groups are matched for. The default
+// user agent looks like a browser, so crawls that keep it use RobotsUserAgent
+func (o Options) robotsAgent() string {
+	if o.UserAgent == "" || o.UserAgent == DefaultUserAgent {
+		return RobotsUserAgent
+	}
+	return o.UserAgent
+}
+
+// productToken returns the name of a user agent like "Other-Bot/1.0", in lower case
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	token, _, _ = strings.Cut(token, " ")
+	return strings.ToLower(token)
+}
+
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	for i, part := range parts[1:] {
+		// The last part of an anchored pattern has to end the path
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx == -1 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+
+	return !anchored || pos == len(path)
+}
+
+type robotsCache struct {
+	client  *http.Client
+	limiter *HostLimiter
+
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+// robotsEntry is the robots.txt of one host. done is closed once robots is set.
+type robotsEntry struct {
+	done   chan struct{}
+	robots *Robots
+}
+
+func newRobotsCache(client *http.Client) *robotsCache {
+	return &robotsCache{
+		client: client,
+		hosts:  make(map[string]*robotsEntry),
+	}
+}
+
+// get fetches robots.txt once per host. Concurrent callers wait for the same
+// fetch, and a fetch that failed is forgotten so the next caller tries again.
+func (rc *robotsCache) get(ctx context.Context, u *url.URL) *Robots {
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	rc.mu.Lock()
+	entry, ok := rc.hosts[key]
+	if ok {
+		rc.mu.Unlock()
+		select {
+		case <-entry.done:
+			return entry.robots
+		case <-ctx.Done():
+			// The caller's own request fails on the same context
+			return &Robots{}
+		}
+	}
+	entry = &robotsEntry{done: make(chan struct{})}
+	rc.hosts[key] = entry
+	rc.mu.Unlock()
+
+	robots, ok := rc.fetch(ctx, key+"/robots.txt")
+	if !ok {
+		rc.mu.Lock()
+		delete(rc.hosts, key)
+		rc.mu.Unlock()
+	}
+	entry.robots = robots
+	close(entry.done)
+	return robots
+}
+
+// fetch downloads and parses robots.txt. ok is false when it could not be read
+// because of a network error, cancellation or a server error.
+func (rc *robotsCache) fetch(ctx context.Context, robotsURL string) (robots *Robots, ok bool) {
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return &Robots{}, false
+	}
+	release, err := rc.limiter.acquire(ctx, req.URL.Host)
+	if err != nil {
+		return &Robots{}, false
+	}
+	defer release()
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		// Leave the page fetch to report the network problem
+		return &Robots{}, false
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &Robots{disallowAll: true}, false
+	case resp.StatusCode >= 400:
+		return &Robots{}, true
+	default:
+		return ParseRobots(resp.Body), true
+	}
+}
+
+func robotsPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
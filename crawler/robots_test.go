@@ -0,0 +1,259 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"go-webcrawler/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobots = `
+# Comments are ignored
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search*q=
+Crawl-delay: 2
+
+User-agent: go-webcrawler
+User-agent: other-bot
+Disallow: /admin
+Allow: /admin/help
+Crawl-delay: 0.5
+
+User-agent: blocked-bot
+Disallow: /
+`
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := ParseRobots(strings.NewReader(testRobots))
+
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		expected  bool
+	}{
+		{"Wildcard group allows root", "some-bot", "/", true},
+		{"Wildcard group disallows directory", "some-bot", "/private/page", false},
+		{"Longer allow wins", "some-bot", "/private/public/page", true},
+		{"End anchor matches", "some-bot", "/files/report.pdf", false},
+		{"End anchor requires end", "some-bot", "/files/report.pdf.html", true},
+		{"Wildcard in the middle", "some-bot", "/search?lang=en&q=go", false},
+		{"Specific group replaces wildcard", "go-webcrawler", "/private/page", true},
+		{"Specific group disallows", "go-webcrawler", "/admin/users", false},
+		{"Specific group allows", "go-webcrawler", "/admin/help", true},
+		{"Shared group", "Other-Bot/1.0", "/admin", false},
+		{"Disallow everything", "blocked-bot", "/anything", false},
+		{"Empty path", "blocked-bot", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := robots.Allowed(tt.userAgent, tt.path)
+			if result != tt.expected {
+				t.Errorf("Allowed(%q, %q) = %v; want %v", tt.userAgent, tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRobotsCrawlDelay(t *testing.T) {
+	robots := ParseRobots(strings.NewReader(testRobots))
+
+	if delay := robots.CrawlDelay("some-bot"); delay != 2*time.Second {
+		t.Errorf("Expected 2s crawl delay, got %v", delay)
+	}
+
+	if delay := robots.CrawlDelay("go-webcrawler"); delay != 500*time.Millisecond {
+		t.Errorf("Expected 500ms crawl delay, got %v", delay)
+	}
+
+	if delay := robots.CrawlDelay("blocked-bot"); delay != 0 {
+		t.Errorf("Expected no crawl delay, got %v", delay)
+	}
+}
+
+func TestRobotsAgentMatch(t *testing.T) {
+	robots := ParseRobots(strings.NewReader(`
+User-agent: *
+Disallow: /private
+
+User-agent:
+User-agent: web
+User-agent: go-webcrawler-extra
+Disallow: /
+`))
+
+	// None of the named groups is our product token, so the * group applies
+	if !robots.Allowed("go-webcrawler", "/page") {
+		t.Error("Expected empty, partial and longer agents not to match go-webcrawler")
+	}
+	if robots.Allowed("go-webcrawler", "/private") {
+		t.Error("Expected the * group to apply to go-webcrawler")
+	}
+	if robots.Allowed("WEB/2.0", "/page") {
+		t.Error("Expected agents to match ignoring case and version")
+	}
+}
+
+func TestRobotsEmpty(t *testing.T) {
+	robots := ParseRobots(strings.NewReader(""))
+
+	if !robots.Allowed("go-webcrawler", "/anything") {
+		t.Error("Expected empty robots.txt to allow everything")
+	}
+}
+
+func TestCrawlURL_BlockedByRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private")
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>Page</title></head></html>`)
+	}))
+	defer server.Close()
+
+	result := CrawlURL(server.URL + "/private/page")
+
+	if result.Success {
+		t.Error("Expected crawl of disallowed URL to fail")
+	}
+	if !result.BlockedByRobots {
+		t.Error("Expected result to be blocked by robots.txt")
+	}
+	if result.Error != "Blocked by robots.txt" {
+		t.Errorf("Expected robots.txt error, got %q", result.Error)
+	}
+
+	result = CrawlURLWithOptions(context.Background(), server.URL+"/private/page", Options{IgnoreRobots: true})
+
+	if !result.Success {
+		t.Errorf("Expected crawl ignoring robots.txt to succeed, got error: %s", result.Error)
+	}
+}
+
+func TestRobotsCache_Retry(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first fetch fails, later ones succeed
+		if fetches.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private")
+	}))
+	defer server.Close()
+
+	cache := newRobotsCache(server.Client())
+	u, _ := url.Parse(server.URL + "/private")
+
+	if cache.get(context.Background(), u).Allowed(RobotsUserAgent, "/page") {
+		t.Error("Expected unavailable robots.txt to disallow everything")
+	}
+	for range 2 {
+		if !cache.get(context.Background(), u).Allowed(RobotsUserAgent, "/page") {
+			t.Error("Expected robots.txt to be fetched again after a server error")
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("Expected 2 fetches, got %d", n)
+	}
+}
+
+func TestCrawlURL_LinksBlockedByRobots(t *testing.T) {
+	var probed atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private")
+		case "/private/report.pdf":
+			probed.Add(1)
+		default:
+			fmt.Fprint(w, `<html><body><a href="/private/report.pdf">Report</a></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	result := CrawlURL(server.URL)
+
+	if len(result.Links) != 1 || result.Links[0].Status != models.LinkBlocked {
+		t.Fatalf("Expected one blocked link, got %+v", result.Links)
+	}
+	if probed.Load() != 0 {
+		t.Error("Expected the blocked link not to be probed")
+	}
+	if result.InaccessibleLinks != 0 {
+		t.Errorf("Expected blocked links not to count as inaccessible, got %d", result.InaccessibleLinks)
+	}
+
+	result = CrawlURLWithOptions(context.Background(), server.URL, Options{IgnoreRobots: true})
+
+	if len(result.Links) != 1 || result.Links[0].Status != models.LinkOK {
+		t.Errorf("Expected the link to be probed when ignoring robots.txt, got %+v", result.Links)
+	}
+}
+
+func TestCrawlURL_RobotsUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: MyBot\nDisallow: /private\n\nUser-agent: *\nDisallow: /other")
+		case "/":
+			fmt.Fprint(w, `<html><body><a href="/private/report.pdf">Report</a></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><head><title>Page</title></head></html>`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		userAgent string
+		blocked   bool
+	}{
+		{"Default user agent", "", false},
+		{"Configured user agent", "MyBot/1.0", true},
+		{"Other user agent", "OtherBot/2.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{UserAgent: tt.userAgent}
+
+			result := CrawlURLWithOptions(context.Background(), server.URL+"/private/page", opts)
+			if result.BlockedByRobots != tt.blocked {
+				t.Errorf("Expected page blocked %v, got %v", tt.blocked, result.BlockedByRobots)
+			}
+
+			result = CrawlURLWithOptions(context.Background(), server.URL, opts)
+			if len(result.Links) != 1 {
+				t.Fatalf("Expected one link, got %+v", result.Links)
+			}
+			if blocked := result.Links[0].Status == models.LinkBlocked; blocked != tt.blocked {
+				t.Errorf("Expected link blocked %v, got status %q", tt.blocked, result.Links[0].Status)
+			}
+		})
+	}
+}
+
+func TestRobotsCache_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	result := CrawlURL(server.URL)
+
+	if !result.BlockedByRobots {
+		t.Error("Expected unavailable robots.txt to block the crawl")
+	}
+}
@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Scope decides which URLs belong to the same site as a page
+type Scope string
+
+const (
+	// ScopeHost is the exact host, including a non-default port
+	ScopeHost Scope = "host"
+	// ScopeSubdomains is the host and every host below it. A leading
+	// "www." is ignored, so www.doruk.com also covers blog.doruk.com.
+	ScopeSubdomains Scope = "subdomains"
+	// ScopeDomain is the registrable domain (eTLD+1) from the public suffix list
+	ScopeDomain Scope = "domain"
+	// ScopePrefix is the host and the directory of the page, for site crawls only
+	ScopePrefix Scope = "prefix"
+)
+
+// inScope reports whether u belongs to the same site as site. Both URLs
+// should be canonical so hosts compare case-insensitively without default ports.
+func inScope(scope Scope, site, u *url.URL) bool {
+	switch scope {
+	case ScopeHost:
+		return u.Host == site.Host
+	case ScopeSubdomains:
+		host := u.Hostname()
+		root := strings.TrimPrefix(site.Hostname(), "www.")
+		return host == root || strings.HasSuffix(host, "."+root)
+	case ScopePrefix:
+		dir := site.Path[:strings.LastIndex(site.Path, "/")+1]
+		return u.Scheme == site.Scheme && u.Host == site.Host && strings.HasPrefix(u.Path, dir)
+	default:
+		return registrableDomain(u.Hostname()) == registrableDomain(site.Hostname())
+	}
+}
+
+// registrableDomain returns the eTLD+1 of host, like doruk.com for
+// blog.doruk.com or doruk.co.uk for www.doruk.co.uk. IP addresses,
+// single labels like localhost and public suffixes are returned as they are.
+func registrableDomain(host string) string {
+	host = strings.TrimSuffix(host, ".")
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestInScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    Scope
+		site     string
+		link     string
+		expected bool
+	}{
+		{"Domain same host", ScopeDomain, "https://doruk.com/", "https://doruk.com/about", true},
+		{"Domain www", ScopeDomain, "https://www.doruk.com/", "https://doruk.com/", true},
+		{"Domain subdomain", ScopeDomain, "https://doruk.com/", "https://blog.doruk.com/", true},
+		{"Domain sibling subdomains", ScopeDomain, "https://shop.doruk.com/", "https://blog.doruk.com/", true},
+		{"Domain in query", ScopeDomain, "https://doruk.com/", "https://evil.com/?q=doruk.com", false},
+		{"Domain as subdomain of another", ScopeDomain, "https://doruk.com/", "https://doruk.com.evil.net/", false},
+		{"Domain lookalike", ScopeDomain, "https://doruk.com/", "https://notdoruk.com/", false},
+		{"Domain multi-label suffix", ScopeDomain, "https://www.doruk.co.uk/", "https://shop.doruk.co.uk/", true},
+		{"Domain other under suffix", ScopeDomain, "https://doruk.co.uk/", "https://other.co.uk/", false},
+		{"Domain private suffix", ScopeDomain, "https://alice.github.io/", "https://bob.github.io/", false},
+		{"Domain IP address", ScopeDomain, "http://127.0.0.1:8080/", "http://127.0.0.1:9090/", true},
+		{"Domain other IP address", ScopeDomain, "http://127.0.0.1/", "http://10.0.0.1/", false},
+		{"Subdomains child", ScopeSubdomains, "https://www.doruk.com/", "https://blog.doruk.com/", true},
+		{"Subdomains parent", ScopeSubdomains, "https://blog.doruk.com/", "https://doruk.com/", false},
+		{"Subdomains sibling", ScopeSubdomains, "https://blog.doruk.com/", "https://shop.doruk.com/", false},
+		{"Subdomains lookalike", ScopeSubdomains, "https://doruk.com/", "https://doruk.com.evil.net/", false},
+		{"Host exact", ScopeHost, "https://doruk.com/", "https://doruk.com/about", true},
+		{"Host www", ScopeHost, "https://doruk.com/", "https://www.doruk.com/", false},
+		{"Host other port", ScopeHost, "http://127.0.0.1:8080/", "http://127.0.0.1:9090/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site, _ := url.Parse(tt.site)
+			link, _ := url.Parse(tt.link)
+			if result := inScope(tt.scope, site, link); result != tt.expected {
+				t.Errorf("inScope(%q, %q, %q) = %v; want %v", tt.scope, tt.site, tt.link, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"doruk.com", "doruk.com"},
+		{"www.blog.doruk.com", "doruk.com"},
+		{"shop.doruk.co.uk", "doruk.co.uk"},
+		{"alice.github.io", "alice.github.io"},
+		{"doruk.com.", "doruk.com"},
+		{"localhost", "localhost"},
+		{"127.0.0.1", "127.0.0.1"},
+		{"::1", "::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if result := registrableDomain(tt.host); result != tt.expected {
+				t.Errorf("registrableDomain(%q) = %q; want %q", tt.host, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package crawler
+
+import (
+	"context"
+	"go-webcrawler/models"
+	"net/url"
+)
+
+const DefaultMaxPages = 50
+
+type SiteOptions struct {
+	Options
+	MaxDepth int
+	MaxPages int
+	Scope    Scope
+}
+
+type queuedPage struct {
+	url   string
+	depth int
+}
+
+func CrawlSite(ctx context.Context, seedURL string, opts SiteOptions) models.SiteResult {
+	opts = withSiteDefaults(opts)
+	seed := NormalizeURL(seedURL)
+
+	site := models.SiteResult{
+		SeedURL: seed,
+	}
+
+	s := newSession(opts.Options)
+
+	seedKey, err := s.canonicalKey(seed)
+	if err != nil {
+		site.Pages = append(site.Pages, s.crawlPage(ctx, seed))
+		site.Summary = summarizeSite(site.Pages)
+		return site
+	}
+	inScope := scopeMatcher(seedKey, opts.Scope)
+
+	seen := map[string]bool{seedKey: true}
+	queue := []queuedPage{{url: seed, depth: 0}}
+
+	for len(queue) > 0 && len(site.Pages) < opts.MaxPages && ctx.Err() == nil {
+		page := queue[0]
+		queue = queue[1:]
+
+		result, links := s.crawl(ctx, page.url)
+		result.Depth = page.depth
+		site.Pages = append(site.Pages, result)
+
+		// Don't crawl the target of a redirect again when it is linked later
+		if key, err := s.canonicalKey(result.FinalURL); err == nil && result.FinalURL != "" {
+			seen[key] = true
+		}
+
+		if !result.Success || page.depth >= opts.MaxDepth {
+			continue
+		}
+
+		for _, link := range links {
+			key, err := s.canonicalKey(link.URL)
+			if err != nil || seen[key] || !inScope(key) {
+				continue
+			}
+			seen[key] = true
+			queue = append(queue, queuedPage{url: key, depth: page.depth + 1})
+		}
+	}
+
+	site.Summary = summarizeSite(site.Pages)
+	site.Summary.Truncated = len(queue) > 0
+	return site
+}
+
+func withSiteDefaults(opts SiteOptions) SiteOptions {
+	// A depth of zero crawls the seed page only
+	opts.MaxDepth = max(opts.MaxDepth, 0)
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = DefaultMaxPages
+	}
+	if opts.Scope == "" {
+		opts.Scope = ScopeHost
+	}
+	return opts
+}
+
+// canonicalKey returns the form used to dedupe pages within a site crawl
+func (s *session) canonicalKey(rawURL string) (string, error) {
+	u, err := s.canon.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func scopeMatcher(seed string, scope Scope) func(string) bool {
+	seedURL, _ := url.Parse(seed)
+
+	return func(candidate string) bool {
+		u, err := url.Parse(candidate)
+		return err == nil && inScope(scope, seedURL, u)
+	}
+}
+
+func summarizeSite(pages []models.CrawlResult) models.SiteSummary {
+	summary := models.SiteSummary{}
+	broken := make(map[string]bool)
+	rules := accessibilitySummary{}
+
+	for _, page := range pages {
+		if page.BlockedByRobots {
+			summary.PagesBlocked++
+			continue
+		}
+		if !page.Success {
+			summary.PagesFailed++
+			continue
+		}
+
+		summary.PagesCrawled++
+		summary.MaxDepthReached = max(summary.MaxDepthReached, page.Depth)
+		summary.InternalLinks += page.InternalLinks
+		summary.ExternalLinks += page.ExternalLinks
+		if page.HasLoginForm {
+			summary.PagesWithLoginForm++
+		}
+		if len(page.Accessibility) > 0 {
+			summary.PagesWithAccessibilityIssues++
+		}
+		rules.add(page.Accessibility)
+
+		for _, link := range page.Links {
+			if link.Broken() {
+				broken[link.URL] = true
+			}
+		}
+	}
+
+	summary.InaccessibleLinks = len(broken)
+	summary.Accessibility = rules.summary()
+	return summary
+}
@@ -0,0 +1,152 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"go-webcrawler/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newSiteServer serves a small site whose home page links to external
+func newSiteServer(external string) *httptest.Server {
+	pages := map[string]string{
+		"/":              `<a href="/about">About</a><a href="/docs/">Docs</a><a href="` + external + `">External</a>`,
+		"/about":         `<a href="/">Home</a><a href="/about#team">Team</a><a href="/missing">Missing</a>`,
+		"/docs/":         `<a href="/docs/intro">Intro</a><form id="login"><input type="password"></form>`,
+		"/docs/intro":    `<a href="/docs/advanced">Advanced</a>`,
+		"/docs/advanced": `<h1>Advanced</h1>`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, "<html><head><title>%s</title></head><body>%s</body></html>", r.URL.Path, body)
+	}))
+}
+
+// newExternalServer answers 404 to everything. It is reached as localhost so
+// it is outside the scope of the 127.0.0.1 site server.
+func newExternalServer() (*httptest.Server, string) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	return server, strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/"
+}
+
+func TestCrawlSite(t *testing.T) {
+	external, externalURL := newExternalServer()
+	defer external.Close()
+	server := newSiteServer(externalURL)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		seed      string
+		opts      SiteOptions
+		pages     int
+		failed    int
+		maxDepth  int
+		truncated bool
+	}{
+		{"Seed only", server.URL, SiteOptions{MaxDepth: 0, MaxPages: 10}, 1, 0, 0, false},
+		{"One level", server.URL, SiteOptions{MaxDepth: 1, MaxPages: 10}, 3, 0, 1, false},
+		{"Whole site", server.URL, SiteOptions{MaxDepth: 5, MaxPages: 10}, 5, 1, 3, false},
+		{"Page limit", server.URL, SiteOptions{MaxDepth: 5, MaxPages: 2}, 2, 0, 1, true},
+		{"Prefix scope", server.URL + "/docs/", SiteOptions{MaxDepth: 5, MaxPages: 10, Scope: ScopePrefix}, 3, 0, 2, false},
+		{"Without links analyzer", server.URL, SiteOptions{Options: Options{DisabledAnalyzers: []string{AnalyzerLinks}}, MaxDepth: 5, MaxPages: 10}, 5, 1, 3, false},
+		{"Only title analyzer", server.URL, SiteOptions{Options: Options{Analyzers: []string{AnalyzerTitle}}, MaxDepth: 5, MaxPages: 2}, 2, 0, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Politeness is tested on its own, here it would only slow things down
+			tt.opts.HostRequestsPerSecond = -1
+			result := CrawlSite(context.Background(), tt.seed, tt.opts)
+
+			if len(result.Pages) != tt.pages+tt.failed {
+				t.Errorf("Expected %d pages, got %d", tt.pages+tt.failed, len(result.Pages))
+			}
+			if result.Summary.PagesCrawled != tt.pages {
+				t.Errorf("Expected %d crawled pages, got %d", tt.pages, result.Summary.PagesCrawled)
+			}
+			if result.Summary.PagesFailed != tt.failed {
+				t.Errorf("Expected %d failed pages, got %d", tt.failed, result.Summary.PagesFailed)
+			}
+			if result.Summary.MaxDepthReached != tt.maxDepth {
+				t.Errorf("Expected max depth %d, got %d", tt.maxDepth, result.Summary.MaxDepthReached)
+			}
+			if result.Summary.Truncated != tt.truncated {
+				t.Errorf("Expected truncated %v, got %v", tt.truncated, result.Summary.Truncated)
+			}
+		})
+	}
+}
+
+func TestCrawlSite_Summary(t *testing.T) {
+	external, externalURL := newExternalServer()
+	defer external.Close()
+	server := newSiteServer(externalURL)
+	defer server.Close()
+
+	result := CrawlSite(context.Background(), server.URL, SiteOptions{
+		Options:  Options{HostRequestsPerSecond: -1},
+		MaxDepth: 1,
+		MaxPages: 10,
+	})
+
+	if result.Summary.PagesWithLoginForm != 1 {
+		t.Errorf("Expected 1 page with login form, got %d", result.Summary.PagesWithLoginForm)
+	}
+
+	// /missing is linked from one page, the external server answers 404
+	if result.Summary.InaccessibleLinks != 2 {
+		t.Errorf("Expected 2 inaccessible links, got %d", result.Summary.InaccessibleLinks)
+	}
+
+	// No page has lang or <main>, the password input on /docs/ has no label
+	if result.Summary.PagesWithAccessibilityIssues != 3 {
+		t.Errorf("Expected 3 pages with accessibility issues, got %d", result.Summary.PagesWithAccessibilityIssues)
+	}
+	want := []models.AccessibilityRuleSummary{
+		{Rule: "label", WCAG: ruleLabel.wcag, Severity: models.SeverityCritical, Findings: 1, Pages: 1},
+		{Rule: "html-lang", WCAG: ruleHTMLLang.wcag, Severity: models.SeveritySerious, Findings: 3, Pages: 3},
+		{Rule: "landmark-main", WCAG: ruleMain.wcag, Severity: models.SeverityModerate, Findings: 3, Pages: 3},
+	}
+	if !reflect.DeepEqual(result.Summary.Accessibility, want) {
+		t.Errorf("Expected accessibility summary %+v, got %+v", want, result.Summary.Accessibility)
+	}
+}
+
+func TestScopeMatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		seed      string
+		scope     Scope
+		candidate string
+		expected  bool
+	}{
+		{"Host match", "https://doruk.com/", ScopeHost, "https://doruk.com/about", true},
+		{"Host mismatch", "https://doruk.com/", ScopeHost, "https://blog.doruk.com/", false},
+		{"Domain subdomain", "https://www.doruk.com/", ScopeDomain, "https://blog.doruk.com/", true},
+		{"Domain lookalike", "https://doruk.com/", ScopeDomain, "https://notdoruk.com/", false},
+		{"Domain suffix lookalike", "https://doruk.com/", ScopeDomain, "https://doruk.com.evil.net/", false},
+		{"Subdomains child", "https://www.doruk.com/", ScopeSubdomains, "https://blog.doruk.com/", true},
+		{"Subdomains parent", "https://blog.doruk.com/", ScopeSubdomains, "https://doruk.com/", false},
+		{"Prefix match", "https://doruk.com/docs/", ScopePrefix, "https://doruk.com/docs/intro", true},
+		{"Prefix mismatch", "https://doruk.com/docs/", ScopePrefix, "https://doruk.com/blog", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scopeMatcher(tt.seed, tt.scope)(tt.candidate)
+			if result != tt.expected {
+				t.Errorf("scopeMatcher(%q, %q)(%q) = %v; want %v", tt.seed, tt.scope, tt.candidate, result, tt.expected)
+			}
+		})
+	}
+}
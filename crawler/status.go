@@ -0,0 +1,211 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusSet is a list of status codes and ranges written like "2xx,304,400-404"
+type StatusSet []statusRange
+
+type statusRange struct {
+	from, to int
+}
+
+var (
+	// DefaultSuccessStatuses count 304 as a success because a revalidated page is still there
+	DefaultSuccessStatuses = MustParseStatusSet("2xx,304")
+	// DefaultParseStatuses leave out 304, which has no body
+	DefaultParseStatuses = MustParseStatusSet("2xx")
+)
+
+func ParseStatusSet(value string) (StatusSet, error) {
+	var set StatusSet
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		r, err := parseStatusRange(part)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, r)
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("empty status list %q", value)
+	}
+	return set, nil
+}
+
+func MustParseStatusSet(value string) StatusSet {
+	set, err := ParseStatusSet(value)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+func parseStatusRange(part string) (statusRange, error) {
+	if class, ok := strings.CutSuffix(part, "xx"); ok {
+		digit, err := strconv.Atoi(class)
+		if err != nil || digit < 1 || digit > 5 {
+			return statusRange{}, fmt.Errorf("invalid status class %q", part)
+		}
+		return statusRange{digit * 100, digit*100 + 99}, nil
+	}
+
+	fromText, toText, isRange := strings.Cut(part, "-")
+	if !isRange {
+		toText = fromText
+	}
+	from, err := strconv.Atoi(fromText)
+	if err != nil {
+		return statusRange{}, fmt.Errorf("invalid status code %q", part)
+	}
+	to, err := strconv.Atoi(toText)
+	if err != nil || from < 100 || to > 599 || from > to {
+		return statusRange{}, fmt.Errorf("invalid status code %q", part)
+	}
+	return statusRange{from, to}, nil
+}
+
+func (s StatusSet) Contains(statusCode int) bool {
+	for _, r := range s {
+		if statusCode >= r.from && statusCode <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s StatusSet) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		switch {
+		case r.from == r.to:
+			parts[i] = strconv.Itoa(r.from)
+		case r.from%100 == 0 && r.to == r.from+99:
+			parts[i] = fmt.Sprintf("%dxx", r.from/100)
+		default:
+			parts[i] = fmt.Sprintf("%d-%d", r.from, r.to)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// UnmarshalJSON accepts "2xx,304" as well as ["2xx", "304"]
+func (s *StatusSet) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("status list must be a string or an array of strings")
+		}
+		list = []string{value}
+	}
+
+	set, err := ParseStatusSet(strings.Join(list, ","))
+	if err != nil {
+		return err
+	}
+	*s = set
+	return nil
+}
+
+func (s StatusSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+var statusDescriptions = map[int]string{
+	http.StatusContinue:                      "The client can continue with the request body",
+	http.StatusSwitchingProtocols:            "The server is switching to another protocol",
+	http.StatusEarlyHints:                    "The server sent hints before the final response",
+	http.StatusOK:                            "The request succeeded",
+	http.StatusCreated:                       "A new resource was created",
+	http.StatusAccepted:                      "The request was accepted but not processed yet",
+	http.StatusNonAuthoritativeInfo:          "The response was modified by a proxy",
+	http.StatusNoContent:                     "The request succeeded without a body",
+	http.StatusResetContent:                  "The request succeeded, the client should reset the form",
+	http.StatusPartialContent:                "Only part of the page was sent",
+	http.StatusMultipleChoices:               "The page is available in several variants",
+	http.StatusMovedPermanently:              "The page has moved to a new URL",
+	http.StatusFound:                         "The page is temporarily at another URL",
+	http.StatusSeeOther:                      "The result is at another URL",
+	http.StatusNotModified:                   "The cached copy of the page is still valid",
+	http.StatusUseProxy:                      "The page must be requested through a proxy",
+	http.StatusTemporaryRedirect:             "The page is temporarily at another URL",
+	http.StatusPermanentRedirect:             "The page has moved to a new URL",
+	http.StatusBadRequest:                    "The server cannot process the request",
+	http.StatusUnauthorized:                  "Authentication is required",
+	http.StatusPaymentRequired:               "Payment is required",
+	http.StatusForbidden:                     "Access to this resource is denied",
+	http.StatusNotFound:                      "The requested page does not exist",
+	http.StatusMethodNotAllowed:              "The request method is not supported for this page",
+	http.StatusNotAcceptable:                 "The page is not available in an acceptable format",
+	http.StatusProxyAuthRequired:             "Authentication with the proxy is required",
+	http.StatusRequestTimeout:                "The server timed out waiting for the request",
+	http.StatusConflict:                      "The request conflicts with the state of the resource",
+	http.StatusGone:                          "The page was removed permanently",
+	http.StatusLengthRequired:                "The request needs a Content-Length header",
+	http.StatusPreconditionFailed:            "A precondition of the request was not met",
+	http.StatusRequestEntityTooLarge:         "The request is too large",
+	http.StatusRequestURITooLong:             "The URL is too long",
+	http.StatusUnsupportedMediaType:          "The request body format is not supported",
+	http.StatusRequestedRangeNotSatisfiable:  "The requested range is not available",
+	http.StatusExpectationFailed:             "The Expect header could not be met",
+	http.StatusMisdirectedRequest:            "The request went to a server that can't answer it",
+	http.StatusUnprocessableEntity:           "The request could not be processed",
+	http.StatusTooEarly:                      "The server won't process a request that might be replayed",
+	http.StatusUpgradeRequired:               "The client must switch to another protocol",
+	http.StatusPreconditionRequired:          "The request must be conditional",
+	http.StatusTooManyRequests:               "Too many requests were sent, the crawler is rate limited",
+	http.StatusRequestHeaderFieldsTooLarge:   "The request headers are too large",
+	http.StatusUnavailableForLegalReasons:    "Access is blocked for legal reasons",
+	http.StatusInternalServerError:           "The server encountered an error",
+	http.StatusNotImplemented:                "The server does not support this request",
+	http.StatusBadGateway:                    "A gateway got an invalid response from the upstream server",
+	http.StatusServiceUnavailable:            "The server is temporarily unable to handle the request",
+	http.StatusGatewayTimeout:                "A gateway timed out waiting for the upstream server",
+	http.StatusHTTPVersionNotSupported:       "The HTTP version is not supported",
+	http.StatusNetworkAuthenticationRequired: "Network authentication is required, for example a captive portal",
+}
+
+// DescribeResponse describes the status code and, for 429 and 503, when to retry
+func DescribeResponse(statusCode int, header http.Header) string {
+	description := GetStatusCodeDescription(statusCode)
+
+	if statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable {
+		if delay, ok := ParseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+			description += fmt.Sprintf(", retry after %s", delay.Round(time.Second))
+		}
+	}
+
+	return description
+}
+
+// ParseRetryAfter reads a Retry-After header in seconds or as an HTTP date
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
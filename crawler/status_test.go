@@ -0,0 +1,147 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatusSet(t *testing.T) {
+	set, err := ParseStatusSet("2xx, 304,400-404")
+	if err != nil {
+		t.Fatalf("ParseStatusSet failed: %v", err)
+	}
+
+	for _, code := range []int{200, 206, 299, 304, 400, 404} {
+		if !set.Contains(code) {
+			t.Errorf("Expected %s to contain %d", set, code)
+		}
+	}
+	for _, code := range []int{199, 301, 305, 405, 500} {
+		if set.Contains(code) {
+			t.Errorf("Expected %s not to contain %d", set, code)
+		}
+	}
+
+	if set.String() != "2xx,304,400-404" {
+		t.Errorf("Expected String() to round trip, got %q", set.String())
+	}
+
+	for _, invalid := range []string{"", "abc", "6xx", "404-400", "99", "2xx,foo"} {
+		if _, err := ParseStatusSet(invalid); err == nil {
+			t.Errorf("Expected ParseStatusSet(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestStatusSetJSON(t *testing.T) {
+	var ov Overrides
+	if err := json.Unmarshal([]byte(`{"success_statuses": ["2xx", "404"], "parse_statuses": "200"}`), &ov); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !ov.SuccessStatuses.Contains(404) || ov.SuccessStatuses.Contains(500) {
+		t.Errorf("Unexpected success statuses %s", ov.SuccessStatuses)
+	}
+	if !ov.ParseStatuses.Contains(200) || ov.ParseStatuses.Contains(201) {
+		t.Errorf("Unexpected parse statuses %s", ov.ParseStatuses)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"-5", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		delay, ok := ParseRetryAfter(tt.value, now)
+		if ok != tt.ok || delay != tt.expected {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, delay, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestDescribeResponse_RetryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"60"}}
+
+	if description := DescribeResponse(503, header); !strings.HasSuffix(description, "retry after 1m0s") {
+		t.Errorf("Expected retry hint for 503, got %q", description)
+	}
+	if description := DescribeResponse(500, header); strings.Contains(description, "retry") {
+		t.Errorf("Expected no retry hint for 500, got %q", description)
+	}
+}
+
+func TestCrawlURL_StatusPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+			return
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/partial":
+			w.WriteHeader(http.StatusPartialContent)
+		case "/proxied":
+			w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		}
+		fmt.Fprintf(w, `<html><head><title>%s</title></head></html>`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     Options
+		success  bool
+		analyzed bool
+	}{
+		{"Non-authoritative", "/proxied", Options{}, true, true},
+		{"Partial content", "/partial", Options{}, true, true},
+		{"No content", "/no-content", Options{}, true, true},
+		{"Not modified", "/not-modified", Options{}, true, false},
+		{"Custom 404", "/missing", Options{}, false, false},
+		{"Analyzed 404", "/missing", Options{AnalyzeErrorPages: true}, false, true},
+		{"404 counted as success", "/missing", Options{SuccessStatuses: MustParseStatusSet("2xx,404"), ParseStatuses: MustParseStatusSet("2xx,404")}, true, true},
+		{"Only 200 parsed", "/partial", Options{ParseStatuses: MustParseStatusSet("200")}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.IgnoreRobots = true
+			result := CrawlURLWithOptions(context.Background(), server.URL+tt.path, tt.opts)
+
+			if result.Success != tt.success {
+				t.Errorf("Expected success %v, got %v (error: %s)", tt.success, result.Success, result.Error)
+			}
+			if result.Analyzed != tt.analyzed {
+				t.Errorf("Expected analyzed %v, got %v", tt.analyzed, result.Analyzed)
+			}
+			if !tt.success && result.Error == "" {
+				t.Error("Expected an error message for an unsuccessful crawl")
+			}
+			if tt.analyzed && tt.path != "/no-content" && result.Title != tt.path {
+				t.Errorf("Expected title %q, got %q", tt.path, result.Title)
+			}
+		})
+	}
+}
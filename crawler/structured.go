@@ -0,0 +1,400 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-webcrawler/models"
+	"mime"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// rdfaPrefixes are the prefixes RDFa documents may use without declaring them
+var rdfaPrefixes = map[string]string{
+	"schema": "https://schema.org/",
+	"og":     "http://ogp.me/ns#",
+	"dc":     "http://purl.org/dc/terms/",
+	"foaf":   "http://xmlns.com/foaf/0.1/",
+}
+
+// ExtractStructuredData finds JSON-LD scripts, Microdata items and RDFa
+// resources and puts them into one shape. URLs in property values are kept as
+// written, not resolved.
+func ExtractStructuredData(n *html.Node) models.StructuredData {
+	d := &structuredData{types: make(map[string]bool)}
+	d.walk(n)
+	d.walkMicrodata(n, nil)
+	d.walkRDFa(n, nil, "", rdfaPrefixes)
+
+	for t := range d.types {
+		d.result.Types = append(d.result.Types, t)
+	}
+	slices.Sort(d.result.Types)
+	return d.result
+}
+
+type structuredData struct {
+	result models.StructuredData
+	// types are the schema.org types seen
+	types map[string]bool
+}
+
+func (d *structuredData) error(format models.DataFormat, n *html.Node, message string, args ...any) {
+	d.result.Errors = append(d.result.Errors, models.StructuredDataError{
+		Format:  format,
+		Message: fmt.Sprintf(message, args...),
+		Path:    domPath(n),
+	})
+}
+
+// term expands a type or property name against vocab. schema.org names are
+// returned short and, for types, recorded. ok is false when a bare name has
+// no vocabulary to expand it with.
+func (d *structuredData) term(name, vocab string, isType bool) (string, bool) {
+	full := name
+	if !strings.Contains(name, ":") {
+		if vocab == "" {
+			return name, false
+		}
+		full = vocab + name
+	}
+
+	short, ok := schemaName(full)
+	if !ok {
+		return full, true
+	}
+	if isType {
+		d.types[short] = true
+	}
+	return short, true
+}
+
+// schemaName returns the name in a schema.org URL like https://schema.org/Product
+func schemaName(iri string) (string, bool) {
+	if name, ok := strings.CutPrefix(iri, "schema:"); ok {
+		return name, true
+	}
+	u, err := url.Parse(iri)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || strings.TrimPrefix(u.Host, "www.") != "schema.org" {
+		return "", false
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	return name, name != ""
+}
+
+// walk finds the JSON-LD scripts
+func (d *structuredData) walk(n *html.Node) {
+	if n.Type == html.ElementNode && n.Data == "script" {
+		if mediaType, _, _ := mime.ParseMediaType(getAttribute(n, "type")); mediaType == "application/ld+json" {
+			d.addJSONLD(n)
+		}
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		d.walk(c)
+	}
+}
+
+func (d *structuredData) addJSONLD(n *html.Node) {
+	var script strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			script.WriteString(c.Data)
+		}
+	}
+	if strings.TrimSpace(script.String()) == "" {
+		d.error(models.FormatJSONLD, n, "Empty JSON-LD script")
+		return
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(script.String()))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		d.error(models.FormatJSONLD, n, "Invalid JSON: %v", err)
+		return
+	}
+	d.jsonLDItems(n, doc, "")
+}
+
+// jsonLDItems adds the top-level items of a JSON-LD document, an object, an
+// array of objects or an object with a @graph
+func (d *structuredData) jsonLDItems(n *html.Node, doc any, vocab string) {
+	switch doc := doc.(type) {
+	case []any:
+		for _, item := range doc {
+			d.jsonLDItems(n, item, vocab)
+		}
+	case map[string]any:
+		if context, ok := doc["@context"]; ok {
+			vocab = jsonLDVocab(context)
+		}
+		if graph, ok := doc["@graph"].([]any); ok {
+			d.jsonLDItems(n, graph, vocab)
+			return
+		}
+		if _, ok := doc["@type"]; !ok {
+			d.error(models.FormatJSONLD, n, "Item without @type")
+		}
+		item := d.jsonLDItem(n, doc, vocab)
+		item.Path = domPath(n)
+		d.result.Items = append(d.result.Items, item)
+	default:
+		d.error(models.FormatJSONLD, n, "Expected an object or an array, got %v", doc)
+	}
+}
+
+func (d *structuredData) jsonLDItem(n *html.Node, object map[string]any, vocab string) models.DataItem {
+	if context, ok := object["@context"]; ok {
+		vocab = jsonLDVocab(context)
+	}
+	item := models.DataItem{Format: models.FormatJSONLD}
+	if id, ok := object["@id"].(string); ok {
+		item.ID = id
+	}
+
+	var types []any
+	switch t := object["@type"].(type) {
+	case string:
+		types = []any{t}
+	case []any:
+		types = t
+	}
+	for _, t := range types {
+		if name, ok := t.(string); ok {
+			itemType, ok := d.term(name, vocab, true)
+			if !ok {
+				d.error(models.FormatJSONLD, n, "Type %q without a @context", name)
+			}
+			item.Types = append(item.Types, itemType)
+		}
+	}
+
+	for key, value := range object {
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+		name, _ := d.term(key, vocab, false)
+		if values := d.jsonLDValues(n, value, vocab); len(values) > 0 {
+			item.Properties = addValues(item.Properties, name, values...)
+		}
+	}
+	return item
+}
+
+func (d *structuredData) jsonLDValues(n *html.Node, value any, vocab string) []models.DataValue {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		var values []models.DataValue
+		for _, v := range value {
+			values = append(values, d.jsonLDValues(n, v, vocab)...)
+		}
+		return values
+	case map[string]any:
+		if v, ok := value["@value"]; ok {
+			return []models.DataValue{{Text: fmt.Sprint(v)}}
+		}
+		if id, ok := value["@id"].(string); ok && len(value) == 1 {
+			return []models.DataValue{{Text: id}}
+		}
+		item := d.jsonLDItem(n, value, vocab)
+		return []models.DataValue{{Item: &item}}
+	default:
+		return []models.DataValue{{Text: fmt.Sprint(value)}}
+	}
+}
+
+// jsonLDVocab returns the vocabulary bare names expand with, from a context
+// like "https://schema.org" or {"@vocab": "https://schema.org/"}
+func jsonLDVocab(context any) string {
+	switch context := context.(type) {
+	case string:
+		if _, ok := schemaName(strings.TrimSuffix(context, "/") + "/Thing"); ok {
+			return "https://schema.org/"
+		}
+		return context
+	case map[string]any:
+		vocab, _ := context["@vocab"].(string)
+		return vocab
+	case []any:
+		for _, c := range context {
+			if vocab := jsonLDVocab(c); vocab != "" {
+				return vocab
+			}
+		}
+	}
+	return ""
+}
+
+// walkMicrodata adds the items of itemscope elements. item is the item the
+// itemprop attributes below n belong to.
+func (d *structuredData) walkMicrodata(n *html.Node, item *models.DataItem) {
+	if n.Type == html.ElementNode {
+		props := strings.Fields(getAttribute(n, "itemprop"))
+		if len(props) > 0 && item == nil {
+			d.error(models.FormatMicrodata, n, "itemprop %q outside an itemscope", props[0])
+		}
+
+		if hasAttribute(n, "itemscope") {
+			child := d.microdataItem(n)
+			if len(props) > 0 && item != nil {
+				for _, prop := range props {
+					name, _ := d.term(prop, "", false)
+					item.Properties = addValues(item.Properties, name, models.DataValue{Item: &child})
+				}
+			} else {
+				d.result.Items = append(d.result.Items, child)
+			}
+			return
+		}
+
+		if len(props) > 0 && item != nil {
+			value := models.DataValue{Text: microdataValue(n)}
+			for _, prop := range props {
+				name, _ := d.term(prop, "", false)
+				item.Properties = addValues(item.Properties, name, value)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		d.walkMicrodata(c, item)
+	}
+}
+
+func (d *structuredData) microdataItem(n *html.Node) models.DataItem {
+	item := models.DataItem{Format: models.FormatMicrodata, ID: getAttribute(n, "itemid"), Path: domPath(n)}
+	for _, t := range strings.Fields(getAttribute(n, "itemtype")) {
+		itemType, ok := d.term(t, "", true)
+		if !ok {
+			d.error(models.FormatMicrodata, n, "itemtype %q is not a URL", t)
+		}
+		item.Types = append(item.Types, itemType)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		d.walkMicrodata(c, &item)
+	}
+	return item
+}
+
+// microdataValue returns an itemprop's value, which depends on the element
+func microdataValue(n *html.Node) string {
+	switch n.Data {
+	case "meta":
+		return getAttribute(n, "content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return getAttribute(n, "src")
+	case "a", "area", "link":
+		return getAttribute(n, "href")
+	case "object":
+		return getAttribute(n, "data")
+	case "data", "meter":
+		return getAttribute(n, "value")
+	case "time":
+		if datetime := getAttribute(n, "datetime"); datetime != "" {
+			return datetime
+		}
+	}
+	return textContent(n)
+}
+
+// walkRDFa adds the resources of typeof elements. vocab and prefixes are
+// inherited from the ancestors, item is the resource properties below n belong to.
+func (d *structuredData) walkRDFa(n *html.Node, item *models.DataItem, vocab string, prefixes map[string]string) {
+	if n.Type == html.ElementNode {
+		if hasAttribute(n, "vocab") {
+			vocab = getAttribute(n, "vocab")
+		}
+		if prefix := getAttribute(n, "prefix"); prefix != "" {
+			prefixes = rdfaPrefixMap(prefixes, prefix)
+		}
+
+		// property outside a typeof, like Open Graph's meta tags, is not an item
+		props := strings.Fields(getAttribute(n, "property"))
+
+		if hasAttribute(n, "typeof") {
+			child := models.DataItem{Format: models.FormatRDFa, ID: getAttribute(n, "resource"), Path: domPath(n)}
+			for _, t := range strings.Fields(getAttribute(n, "typeof")) {
+				itemType, ok := d.term(expandPrefix(t, prefixes), vocab, true)
+				if !ok {
+					d.error(models.FormatRDFa, n, "typeof %q without a vocab", t)
+				}
+				child.Types = append(child.Types, itemType)
+			}
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				d.walkRDFa(c, &child, vocab, prefixes)
+			}
+
+			if len(props) > 0 && item != nil {
+				for _, prop := range props {
+					name, _ := d.term(expandPrefix(prop, prefixes), vocab, false)
+					item.Properties = addValues(item.Properties, name, models.DataValue{Item: &child})
+				}
+			} else {
+				d.result.Items = append(d.result.Items, child)
+			}
+			return
+		}
+
+		if len(props) > 0 && item != nil {
+			value := models.DataValue{Text: rdfaValue(n)}
+			for _, prop := range props {
+				name, _ := d.term(expandPrefix(prop, prefixes), vocab, false)
+				item.Properties = addValues(item.Properties, name, value)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		d.walkRDFa(c, item, vocab, prefixes)
+	}
+}
+
+// rdfaValue returns a property's value, content wins over links and the text
+func rdfaValue(n *html.Node) string {
+	for _, key := range []string{"content", "href", "src", "resource", "datetime"} {
+		if hasAttribute(n, key) {
+			return getAttribute(n, key)
+		}
+	}
+	return textContent(n)
+}
+
+// rdfaPrefixMap adds the prefixes of a prefix attribute like
+// "dc: http://purl.org/dc/terms/ ex: https://example.com/ns#"
+func rdfaPrefixMap(prefixes map[string]string, attr string) map[string]string {
+	merged := make(map[string]string, len(prefixes))
+	for prefix, iri := range prefixes {
+		merged[prefix] = iri
+	}
+	fields := strings.Fields(attr)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if prefix, ok := strings.CutSuffix(fields[i], ":"); ok {
+			merged[strings.ToLower(prefix)] = fields[i+1]
+		}
+	}
+	return merged
+}
+
+// expandPrefix turns "schema:Person" into the full URL when schema is a known prefix
+func expandPrefix(name string, prefixes map[string]string) string {
+	prefix, local, ok := strings.Cut(name, ":")
+	if !ok {
+		return name
+	}
+	if iri, ok := prefixes[strings.ToLower(prefix)]; ok {
+		return iri + local
+	}
+	return name
+}
+
+func addValues(properties map[string][]models.DataValue, name string, values ...models.DataValue) map[string][]models.DataValue {
+	if properties == nil {
+		properties = make(map[string][]models.DataValue)
+	}
+	properties[name] = append(properties[name], values...)
+	return properties
+}
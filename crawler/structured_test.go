@@ -0,0 +1,167 @@
+package crawler
+
+import (
+	"go-webcrawler/models"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractStructuredData(t *testing.T) {
+	tests := []struct {
+		name    string
+		htmlStr string
+		want    []models.DataItem
+		types   []string
+	}{
+		{
+			"JSON-LD",
+			`<script type="application/ld+json">{
+				"@context": "https://schema.org",
+				"@type": "Product",
+				"@id": "#shoe",
+				"name": "Shoe",
+				"offers": {"@type": "Offer", "price": 59.9, "priceCurrency": "EUR"},
+				"sku": ["a1", "a2"]
+			}</script>`,
+			[]models.DataItem{{
+				Format: models.FormatJSONLD, Types: []string{"Product"}, ID: "#shoe", Path: "html > head > script",
+				Properties: map[string][]models.DataValue{
+					"name": {{Text: "Shoe"}},
+					"offers": {{Item: &models.DataItem{
+						Format: models.FormatJSONLD, Types: []string{"Offer"},
+						Properties: map[string][]models.DataValue{"price": {{Text: "59.9"}}, "priceCurrency": {{Text: "EUR"}}},
+					}}},
+					"sku": {{Text: "a1"}, {Text: "a2"}},
+				},
+			}},
+			[]string{"Offer", "Product"},
+		},
+		{
+			"JSON-LD graph",
+			`<script type="application/ld+json">{"@context": {"@vocab": "http://schema.org/"}, "@graph": [
+				{"@type": "WebSite", "url": {"@id": "https://example.com/"}},
+				{"@type": "BreadcrumbList", "itemListElement": []}
+			]}</script>`,
+			[]models.DataItem{
+				{Format: models.FormatJSONLD, Types: []string{"WebSite"}, Path: "html > head > script",
+					Properties: map[string][]models.DataValue{"url": {{Text: "https://example.com/"}}}},
+				{Format: models.FormatJSONLD, Types: []string{"BreadcrumbList"}, Path: "html > head > script"},
+			},
+			[]string{"BreadcrumbList", "WebSite"},
+		},
+		{
+			"Microdata",
+			`<div itemscope itemtype="https://schema.org/Article" itemid="urn:article:1">
+				<h1 itemprop="headline">News</h1>
+				<time itemprop="datePublished" datetime="2024-05-01">May 1</time>
+				<div itemprop="author" itemscope itemtype="http://schema.org/Person">
+					<a itemprop="url" href="/authors/ada"><span itemprop="name">Ada</span></a>
+				</div>
+				<meta itemprop="inLanguage keywords" content="en">
+			</div>`,
+			[]models.DataItem{{
+				Format: models.FormatMicrodata, Types: []string{"Article"}, ID: "urn:article:1", Path: "html > body > div",
+				Properties: map[string][]models.DataValue{
+					"headline":      {{Text: "News"}},
+					"datePublished": {{Text: "2024-05-01"}},
+					"author": {{Item: &models.DataItem{
+						Format: models.FormatMicrodata, Types: []string{"Person"}, Path: "html > body > div > div",
+						Properties: map[string][]models.DataValue{"url": {{Text: "/authors/ada"}}, "name": {{Text: "Ada"}}},
+					}}},
+					"inLanguage": {{Text: "en"}},
+					"keywords":   {{Text: "en"}},
+				},
+			}},
+			[]string{"Article", "Person"},
+		},
+		{
+			"RDFa",
+			`<ol vocab="https://schema.org/" typeof="BreadcrumbList">
+				<li property="itemListElement" typeof="ListItem">
+					<a property="item" href="/books">Books</a><meta property="position" content="1">
+				</li>
+			</ol>
+			<div prefix="ex: https://example.com/ns#" typeof="ex:Thing schema:Event"><span property="schema:name">Launch</span></div>`,
+			[]models.DataItem{
+				{
+					Format: models.FormatRDFa, Types: []string{"BreadcrumbList"}, Path: "html > body > ol",
+					Properties: map[string][]models.DataValue{
+						"itemListElement": {{Item: &models.DataItem{
+							Format: models.FormatRDFa, Types: []string{"ListItem"}, Path: "html > body > ol > li",
+							Properties: map[string][]models.DataValue{"item": {{Text: "/books"}}, "position": {{Text: "1"}}},
+						}}},
+					},
+				},
+				{
+					Format: models.FormatRDFa, Types: []string{"https://example.com/ns#Thing", "Event"}, Path: "html > body > div",
+					Properties: map[string][]models.DataValue{"name": {{Text: "Launch"}}},
+				},
+			},
+			[]string{"BreadcrumbList", "Event", "ListItem"},
+		},
+		{
+			"Open Graph is not RDFa",
+			`<meta property="og:title" content="Home">`,
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.htmlStr))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data := ExtractStructuredData(doc)
+			if len(data.Errors) > 0 {
+				t.Errorf("Expected no errors, got %+v", data.Errors)
+			}
+			if !reflect.DeepEqual(data.Items, tt.want) {
+				t.Errorf("Expected items %+v, got %+v", tt.want, data.Items)
+			}
+			if !reflect.DeepEqual(data.Types, tt.types) {
+				t.Errorf("Expected types %v, got %v", tt.types, data.Types)
+			}
+		})
+	}
+}
+
+func TestExtractStructuredData_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		htmlStr string
+		format  models.DataFormat
+		message string
+	}{
+		{"Invalid JSON", `<script type="application/ld+json">{"@type": "Product",}</script>`, models.FormatJSONLD, "Invalid JSON"},
+		{"Empty script", `<script type="application/ld+json"> </script>`, models.FormatJSONLD, "Empty JSON-LD script"},
+		{"No type", `<script type="application/ld+json">{"@context": "https://schema.org", "name": "x"}</script>`, models.FormatJSONLD, "Item without @type"},
+		{"No context", `<script type="application/ld+json">{"@type": "Product"}</script>`, models.FormatJSONLD, "without a @context"},
+		{"Not an object", `<script type="application/ld+json">"Product"</script>`, models.FormatJSONLD, "Expected an object"},
+		{"Relative itemtype", `<div itemscope itemtype="Product"></div>`, models.FormatMicrodata, "is not a URL"},
+		{"Stray itemprop", `<span itemprop="name">x</span>`, models.FormatMicrodata, "outside an itemscope"},
+		{"No vocab", `<div typeof="Person"></div>`, models.FormatRDFa, "without a vocab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.htmlStr))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errors := ExtractStructuredData(doc).Errors
+			if len(errors) != 1 {
+				t.Fatalf("Expected 1 error, got %+v", errors)
+			}
+			if errors[0].Format != tt.format || !strings.Contains(errors[0].Message, tt.message) {
+				t.Errorf("Expected a %s error containing %q, got %+v", tt.format, tt.message, errors[0])
+			}
+		})
+	}
+}
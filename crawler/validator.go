@@ -12,32 +12,38 @@ func IsValidURL(url string) bool {
 	return re.MatchString(url)
 }
 
-func NormalizeURL(url string) string {
-	url = strings.TrimSpace(url)
-	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
-		return url
+// NormalizeURL adds https:// to URLs without a scheme and canonicalizes them
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	lower := strings.ToLower(rawURL)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		rawURL = "https://" + rawURL
 	}
-	return "https://" + url
+
+	u, err := Canonicalizer{}.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.String()
 }
 
 func GetStatusCodeDescription(statusCode int) string {
-	switch statusCode {
-	case http.StatusBadRequest:
-		return "Bad Request - The server cannot process the request"
-	case http.StatusUnauthorized:
-		return "Unauthorized - Authentication is required"
-	case http.StatusForbidden:
-		return "Forbidden - Access to this resource is denied"
-	case http.StatusNotFound:
-		return "Not Found - The requested page does not exist"
-	case http.StatusInternalServerError:
-		return "Internal Server Error - The server encountered an error"
+	if description, ok := statusDescriptions[statusCode]; ok {
+		return http.StatusText(statusCode) + " - " + description
+	}
+
+	switch {
+	case statusCode >= 100 && statusCode < 200:
+		return "Informational - The request is still being processed"
+	case statusCode >= 200 && statusCode < 300:
+		return "Success - The request succeeded"
+	case statusCode >= 300 && statusCode < 400:
+		return "Redirection - The page is at another URL"
+	case statusCode >= 400 && statusCode < 500:
+		return "Client Error - There's an issue with the request"
+	case statusCode >= 500 && statusCode < 600:
+		return "Server Error - The server encountered an error"
 	default:
-		if statusCode >= 400 && statusCode < 500 {
-			return "Client Error - There's an issue with the request"
-		} else if statusCode >= 500 {
-			return "Server Error - The server encountered an error"
-		}
 		return "Unexpected status code"
 	}
 }
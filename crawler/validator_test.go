@@ -36,11 +36,14 @@ func TestNormalizeURL(t *testing.T) {
 		input    string
 		expected string
 	}{
-		{"Already HTTP", "http://doruk.com", "http://doruk.com"},
-		{"Already HTTPS", "https://doruk.com", "https://doruk.com"},
-		{"No protocol", "doruk.com", "https://doruk.com"},
-		{"With spaces", "  doruk.com  ", "https://doruk.com"},
-		{"Mixed case", "Doruk.COM", "https://Doruk.COM"},
+		{"Already HTTP", "http://doruk.com", "http://doruk.com/"},
+		{"Already HTTPS", "https://doruk.com", "https://doruk.com/"},
+		{"No protocol", "doruk.com", "https://doruk.com/"},
+		{"With spaces", "  doruk.com  ", "https://doruk.com/"},
+		{"Mixed case", "Doruk.COM", "https://doruk.com/"},
+		{"Uppercase scheme", "HTTPS://doruk.com/Path", "https://doruk.com/Path"},
+		{"Default port", "http://doruk.com:80/a", "http://doruk.com/a"},
+		{"Fragment", "https://doruk.com/a#top", "https://doruk.com/a"},
 	}
 
 	for _, tt := range tests {
@@ -65,8 +68,15 @@ func TestGetStatusCodeDescription(t *testing.T) {
 		{"Not Found", 404, "Not Found - The requested page does not exist"},
 		{"Internal Server Error", 500, "Internal Server Error - The server encountered an error"},
 		{"Other 4xx", 418, "Client Error - There's an issue with the request"},
-		{"Other 5xx", 502, "Server Error - The server encountered an error"},
-		{"Unexpected", 200, "Unexpected status code"},
+		{"Other 5xx", 599, "Server Error - The server encountered an error"},
+		{"Unexpected", 999, "Unexpected status code"},
+		{"OK", 200, "OK - The request succeeded"},
+		{"Not Modified", 304, "Not Modified - The cached copy of the page is still valid"},
+		{"Gone", 410, "Gone - The page was removed permanently"},
+		{"Too Many Requests", 429, "Too Many Requests - Too many requests were sent, the crawler is rate limited"},
+		{"Legal Reasons", 451, "Unavailable For Legal Reasons - Access is blocked for legal reasons"},
+		{"Bad Gateway", 502, "Bad Gateway - A gateway got an invalid response from the upstream server"},
+		{"Service Unavailable", 503, "Service Unavailable - The server is temporarily unable to handle the request"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"go-webcrawler/models"
+	"sort"
+	"time"
+)
+
+type StringChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type IntChange struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
+
+type BoolChange struct {
+	Before bool `json:"before"`
+	After  bool `json:"after"`
+}
+
+type HeadingDelta struct {
+	Level  string `json:"level"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+	Delta  int    `json:"delta"`
+}
+
+type Diff struct {
+	URL          string         `json:"url"`
+	Before       time.Time      `json:"before"`
+	After        time.Time      `json:"after"`
+	StatusCode   *IntChange     `json:"status_code,omitempty"`
+	Success      *BoolChange    `json:"success,omitempty"`
+	Rendered     *BoolChange    `json:"rendered,omitempty"`
+	Title        *StringChange  `json:"title,omitempty"`
+	DocType      *StringChange  `json:"doctype,omitempty"`
+	HTMLVersion  *StringChange  `json:"html_version,omitempty"`
+	LoginForm    *BoolChange    `json:"login_form,omitempty"`
+	Headings     []HeadingDelta `json:"headings,omitempty"`
+	LinksAdded   []string       `json:"links_added,omitempty"`
+	LinksRemoved []string       `json:"links_removed,omitempty"`
+}
+
+func Results(before, after models.CrawlResult) Diff {
+	d := Diff{
+		URL:    after.URL,
+		Before: before.CrawledAt,
+		After:  after.CrawledAt,
+	}
+
+	if before.StatusCode != after.StatusCode {
+		d.StatusCode = &IntChange{Before: before.StatusCode, After: after.StatusCode}
+	}
+	if before.Success != after.Success {
+		d.Success = &BoolChange{Before: before.Success, After: after.Success}
+	}
+	if before.Rendered != after.Rendered {
+		d.Rendered = &BoolChange{Before: before.Rendered, After: after.Rendered}
+	}
+	if before.Title != after.Title {
+		d.Title = &StringChange{Before: before.Title, After: after.Title}
+	}
+	if before.DocType != after.DocType {
+		d.DocType = &StringChange{Before: before.DocType, After: after.DocType}
+	}
+	if before.HTMLVersion != after.HTMLVersion {
+		d.HTMLVersion = &StringChange{Before: before.HTMLVersion, After: after.HTMLVersion}
+	}
+	if before.HasLoginForm != after.HasLoginForm {
+		d.LoginForm = &BoolChange{Before: before.HasLoginForm, After: after.HasLoginForm}
+	}
+
+	d.Headings = headingDeltas(before.Headings, after.Headings)
+	d.LinksAdded, d.LinksRemoved = linkChanges(before.Links, after.Links)
+
+	return d
+}
+
+func (d Diff) Changed() bool {
+	return d.StatusCode != nil || d.Success != nil || d.Rendered != nil || d.Title != nil || d.DocType != nil ||
+		d.HTMLVersion != nil || d.LoginForm != nil || len(d.Headings) > 0 ||
+		len(d.LinksAdded) > 0 || len(d.LinksRemoved) > 0
+}
+
+func headingDeltas(before, after map[string]int) []HeadingDelta {
+	var deltas []HeadingDelta
+	for _, level := range []string{"h1", "h2", "h3", "h4", "h5", "h6"} {
+		if before[level] != after[level] {
+			deltas = append(deltas, HeadingDelta{
+				Level:  level,
+				Before: before[level],
+				After:  after[level],
+				Delta:  after[level] - before[level],
+			})
+		}
+	}
+	return deltas
+}
+
+func linkChanges(before, after []models.Link) ([]string, []string) {
+	beforeURLs := linkURLs(before)
+	afterURLs := linkURLs(after)
+
+	var added, removed []string
+	for url := range afterURLs {
+		if !beforeURLs[url] {
+			added = append(added, url)
+		}
+	}
+	for url := range beforeURLs {
+		if !afterURLs[url] {
+			removed = append(removed, url)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func linkURLs(links []models.Link) map[string]bool {
+	urls := make(map[string]bool, len(links))
+	for _, link := range links {
+		if link.Web() {
+			urls[link.URL] = true
+		}
+	}
+	return urls
+}
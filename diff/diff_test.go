@@ -0,0 +1,119 @@
+package diff
+
+import (
+	"go-webcrawler/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResults(t *testing.T) {
+	before := models.CrawlResult{
+		URL:          "https://doruk.com",
+		CrawledAt:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		StatusCode:   200,
+		Success:      true,
+		Title:        "Old Title",
+		HTMLVersion:  "HTML 4.01 Strict",
+		DocType:      "html public \"-//w3c//dtd html 4.01//en\"",
+		Headings:     map[string]int{"h1": 1, "h2": 3},
+		HasLoginForm: true,
+		Links: []models.Link{
+			{URL: "https://doruk.com/a"},
+			{URL: "https://doruk.com/b"},
+		},
+	}
+
+	after := models.CrawlResult{
+		URL:         "https://doruk.com",
+		CrawledAt:   time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		StatusCode:  200,
+		Success:     true,
+		Title:       "New Title",
+		HTMLVersion: "HTML5",
+		DocType:     "html",
+		Headings:    map[string]int{"h1": 2, "h2": 3, "h4": 1},
+		Links: []models.Link{
+			{URL: "https://doruk.com/b"},
+			{URL: "https://doruk.com/d"},
+			{URL: "https://doruk.com/c"},
+		},
+	}
+
+	d := Results(before, after)
+
+	if !d.Changed() {
+		t.Error("Expected diff to report changes")
+	}
+	if d.StatusCode != nil || d.Success != nil {
+		t.Error("Expected no status change")
+	}
+	if d.Title == nil || d.Title.Before != "Old Title" || d.Title.After != "New Title" {
+		t.Errorf("Unexpected title change: %+v", d.Title)
+	}
+	if d.DocType == nil || d.DocType.After != "html" {
+		t.Errorf("Unexpected doctype change: %+v", d.DocType)
+	}
+	if d.HTMLVersion == nil || d.HTMLVersion.After != "HTML5" {
+		t.Errorf("Unexpected HTML version change: %+v", d.HTMLVersion)
+	}
+	if d.LoginForm == nil || !d.LoginForm.Before || d.LoginForm.After {
+		t.Errorf("Expected login form to disappear, got %+v", d.LoginForm)
+	}
+
+	expectedHeadings := []HeadingDelta{
+		{Level: "h1", Before: 1, After: 2, Delta: 1},
+		{Level: "h4", Before: 0, After: 1, Delta: 1},
+	}
+	if !reflect.DeepEqual(d.Headings, expectedHeadings) {
+		t.Errorf("Headings = %+v; want %+v", d.Headings, expectedHeadings)
+	}
+
+	if !reflect.DeepEqual(d.LinksAdded, []string{"https://doruk.com/c", "https://doruk.com/d"}) {
+		t.Errorf("Unexpected added links: %v", d.LinksAdded)
+	}
+	if !reflect.DeepEqual(d.LinksRemoved, []string{"https://doruk.com/a"}) {
+		t.Errorf("Unexpected removed links: %v", d.LinksRemoved)
+	}
+}
+
+func TestResults_StatusChange(t *testing.T) {
+	before := models.CrawlResult{StatusCode: 200, Success: true}
+	after := models.CrawlResult{StatusCode: 503, Success: false}
+
+	d := Results(before, after)
+
+	if d.StatusCode == nil || d.StatusCode.Before != 200 || d.StatusCode.After != 503 {
+		t.Errorf("Unexpected status code change: %+v", d.StatusCode)
+	}
+	if d.Success == nil || !d.Success.Before || d.Success.After {
+		t.Errorf("Unexpected success change: %+v", d.Success)
+	}
+}
+
+func TestResults_RenderedChange(t *testing.T) {
+	before := models.CrawlResult{Title: "Loading", Success: true}
+	after := models.CrawlResult{Title: "App", Rendered: true, Success: true}
+
+	d := Results(before, after)
+
+	if d.Rendered == nil || d.Rendered.Before || !d.Rendered.After {
+		t.Errorf("Unexpected rendered change: %+v", d.Rendered)
+	}
+	if !d.Changed() {
+		t.Error("Expected a static and a rendered crawl to differ")
+	}
+}
+
+func TestResults_NoChange(t *testing.T) {
+	result := models.CrawlResult{
+		StatusCode: 200,
+		Title:      "Same",
+		Headings:   map[string]int{"h1": 1},
+		Links:      []models.Link{{URL: "https://doruk.com/a"}},
+	}
+
+	if d := Results(result, result); d.Changed() {
+		t.Errorf("Expected no changes, got %+v", d)
+	}
+}
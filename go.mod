@@ -4,6 +4,7 @@ go 1.23.5
 
 require (
 	github.com/gin-gonic/gin v1.10.1
+	go.etcd.io/bbolt v1.4.3
 	golang.org/x/net v0.43.0
 )
 
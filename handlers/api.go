@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/models"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	MaxBatchSize    = 20
+	MaxBatchWorkers = 5
+)
+
+// Limits for per-request options, so a single request can't hold a worker for long
+const (
+	MaxRequestTimeout    = time.Minute
+	MaxRequestAttempts   = 5
+	MaxRequestRetryDelay = time.Minute
+	MaxRequestRedirects  = 20
+	MaxRequestHeaders    = 20
+	// MaxRequestHeaderSize limits the names and values of all headers together, in bytes
+	MaxRequestHeaderSize = 8 * 1024
+)
+
+const (
+	ErrCodeInvalidBody      = "invalid_body"
+	ErrCodeMissingURL       = "missing_url"
+	ErrCodeInvalidURL       = "invalid_url"
+	ErrCodeEmptyBatch       = "empty_batch"
+	ErrCodeBatchTooLarge    = "batch_too_large"
+	ErrCodeValidationFailed = "validation_failed"
+	ErrCodeInvalidOptions   = "invalid_options"
+	ErrCodeOptionNotAllowed = "option_not_allowed"
+)
+
+type CrawlRequest struct {
+	URL          string             `json:"url"`
+	IgnoreRobots bool               `json:"ignore_robots"`
+	Options      *crawler.Overrides `json:"options,omitempty"`
+}
+
+type BatchCrawlRequest struct {
+	URLs         []string           `json:"urls"`
+	IgnoreRobots bool               `json:"ignore_robots"`
+	Options      *crawler.Overrides `json:"options,omitempty"`
+}
+
+type BatchCrawlResponse struct {
+	Results []models.CrawlResult `json:"results"`
+}
+
+type APIError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+func (h *Handler) CrawlAPI(c *gin.Context) {
+	var req CrawlRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithAPIError(c, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidBody,
+			Message: fmt.Sprintf("Request body must be a JSON object: %v", err),
+		})
+		return
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if fieldErr := validateURL("url", req.URL); fieldErr != nil {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, APIError{
+			Code:    fieldErr.Code,
+			Message: fieldErr.Message,
+			Details: []FieldError{*fieldErr},
+		})
+		return
+	}
+
+	opts, fieldErr := h.crawlerOptions(req.IgnoreRobots, req.Options)
+	if fieldErr != nil {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, APIError{
+			Code:    fieldErr.Code,
+			Message: fieldErr.Message,
+			Details: []FieldError{*fieldErr},
+		})
+		return
+	}
+
+	result := crawler.CrawlURLWithOptions(c.Request.Context(), req.URL, opts)
+	h.record(result)
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) BatchCrawlAPI(c *gin.Context) {
+	var req BatchCrawlRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithAPIError(c, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidBody,
+			Message: fmt.Sprintf("Request body must be a JSON object: %v", err),
+		})
+		return
+	}
+
+	if len(req.URLs) == 0 {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, APIError{
+			Code:    ErrCodeEmptyBatch,
+			Message: "Please provide at least one URL in urls",
+		})
+		return
+	}
+
+	if len(req.URLs) > MaxBatchSize {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, APIError{
+			Code:    ErrCodeBatchTooLarge,
+			Message: fmt.Sprintf("A batch can contain at most %d URLs, got %d", MaxBatchSize, len(req.URLs)),
+		})
+		return
+	}
+
+	var details []FieldError
+	for i, url := range req.URLs {
+		req.URLs[i] = strings.TrimSpace(url)
+		if fieldErr := validateURL(fmt.Sprintf("urls[%d]", i), req.URLs[i]); fieldErr != nil {
+			details = append(details, *fieldErr)
+		}
+	}
+
+	opts, fieldErr := h.crawlerOptions(req.IgnoreRobots, req.Options)
+	if fieldErr != nil {
+		details = append(details, *fieldErr)
+	}
+
+	if len(details) > 0 {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, APIError{
+			Code:    ErrCodeValidationFailed,
+			Message: "One or more fields are invalid",
+			Details: details,
+		})
+		return
+	}
+
+	results := crawlBatch(c.Request.Context(), req.URLs, opts)
+	for _, result := range results {
+		h.record(result)
+	}
+
+	c.JSON(http.StatusOK, BatchCrawlResponse{
+		Results: results,
+	})
+}
+
+func crawlBatch(ctx context.Context, urls []string, opts crawler.Options) []models.CrawlResult {
+	results := make([]models.CrawlResult, len(urls))
+	sem := make(chan struct{}, MaxBatchWorkers)
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, url string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = crawler.CrawlURLWithOptions(ctx, url, opts)
+		}(i, url)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// crawlerOptions applies per-request overrides to the handler's options
+func (h *Handler) crawlerOptions(ignoreRobots bool, ov *crawler.Overrides) (crawler.Options, *FieldError) {
+	opts := h.Options
+	if ov != nil {
+		// Reading arbitrary files from the server is not something API users get to do
+		if ov.CABundle != nil {
+			return opts, &FieldError{
+				Field:   "options.ca_bundle",
+				Code:    ErrCodeOptionNotAllowed,
+				Message: "The CA bundle can only be set in the server configuration",
+			}
+		}
+		// Nor do they get to route requests through other machines or turn off certificate checks
+		if ov.ProxyURL != nil {
+			return opts, &FieldError{
+				Field:   "options.proxy_url",
+				Code:    ErrCodeOptionNotAllowed,
+				Message: "The proxy can only be set in the server configuration",
+			}
+		}
+		if ov.InsecureSkipVerify != nil {
+			return opts, &FieldError{
+				Field:   "options.insecure_skip_verify",
+				Code:    ErrCodeOptionNotAllowed,
+				Message: "TLS verification can only be changed in the server configuration",
+			}
+		}
+		// Nor do they get to point the crawler at another DevTools endpoint
+		if ov.ChromeURL != nil {
+			return opts, &FieldError{
+				Field:   "options.chrome_url",
+				Code:    ErrCodeOptionNotAllowed,
+				Message: "The Chrome URL can only be set in the server configuration",
+			}
+		}
+		if fieldErr := checkLimits(*ov); fieldErr != nil {
+			return opts, fieldErr
+		}
+		opts = opts.Apply(*ov)
+	}
+	if ignoreRobots {
+		opts.IgnoreRobots = true
+	}
+
+	if err := opts.Validate(); err != nil {
+		return opts, &FieldError{
+			Field:   "options",
+			Code:    ErrCodeInvalidOptions,
+			Message: err.Error(),
+		}
+	}
+	return opts, nil
+}
+
+// checkLimits rejects per-request options beyond what the server allows
+func checkLimits(ov crawler.Overrides) *FieldError {
+	tooLarge := func(field, message string) *FieldError {
+		return &FieldError{
+			Field:   "options." + field,
+			Code:    ErrCodeInvalidOptions,
+			Message: message,
+		}
+	}
+
+	durations := []struct {
+		field string
+		value *crawler.Duration
+		max   time.Duration
+	}{
+		{"timeout", ov.Timeout, MaxRequestTimeout},
+		{"connect_timeout", ov.ConnectTimeout, MaxRequestTimeout},
+		{"read_timeout", ov.ReadTimeout, MaxRequestTimeout},
+		{"link_timeout", ov.LinkTimeout, MaxRequestTimeout},
+		{"render_idle", ov.RenderIdle, MaxRequestTimeout},
+		{"retry_base_delay", ov.RetryBaseDelay, MaxRequestRetryDelay},
+		{"retry_max_delay", ov.RetryMaxDelay, MaxRequestRetryDelay},
+		{"min_host_delay", ov.MinHostDelay, MaxRequestRetryDelay},
+	}
+	for _, d := range durations {
+		if d.value != nil && time.Duration(*d.value) > d.max {
+			return tooLarge(d.field, fmt.Sprintf("%s can be at most %v", d.field, d.max))
+		}
+	}
+
+	if ov.MaxAttempts != nil && *ov.MaxAttempts > MaxRequestAttempts {
+		return tooLarge("max_attempts", fmt.Sprintf("max_attempts can be at most %d", MaxRequestAttempts))
+	}
+	if ov.MaxRedirects != nil && *ov.MaxRedirects > MaxRequestRedirects {
+		return tooLarge("max_redirects", fmt.Sprintf("max_redirects can be at most %d", MaxRequestRedirects))
+	}
+
+	if len(ov.Headers) > MaxRequestHeaders {
+		return tooLarge("headers", fmt.Sprintf("At most %d headers can be set", MaxRequestHeaders))
+	}
+	size := 0
+	for name, value := range ov.Headers {
+		size += len(name) + len(value)
+	}
+	if size > MaxRequestHeaderSize {
+		return tooLarge("headers", fmt.Sprintf("Headers can be at most %d bytes together", MaxRequestHeaderSize))
+	}
+
+	return nil
+}
+
+func validateURL(field, url string) *FieldError {
+	if url == "" {
+		return &FieldError{
+			Field:   field,
+			Code:    ErrCodeMissingURL,
+			Message: "Please enter a URL!",
+		}
+	}
+
+	if !crawler.IsValidURL(url) {
+		return &FieldError{
+			Field:   field,
+			Code:    ErrCodeInvalidURL,
+			Message: "Please enter a valid URL (must start with http:// or https://)",
+		}
+	}
+
+	return nil
+}
+
+func abortWithAPIError(c *gin.Context, status int, apiErr APIError) {
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: apiErr})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-webcrawler/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func postJSON(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newTestSite() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>API Page</title></head><body><h1>Hi</h1></body></html>`)
+	}))
+}
+
+func TestCrawlAPIHandler(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	router := setupTestRouter(newTestHandler(t))
+	w := postJSON(router, "/api/v1/crawl", fmt.Sprintf(`{"url": %q}`, server.URL))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var result models.CrawlResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !result.Success {
+		t.Errorf("Expected successful crawl, got error: %s", result.Error)
+	}
+	if result.Title != "API Page" {
+		t.Errorf("Expected title 'API Page', got %q", result.Title)
+	}
+	if !strings.Contains(w.Body.String(), `"html_version":"HTML5"`) {
+		t.Error("Expected snake_case json fields in response")
+	}
+}
+
+func TestCrawlAPIHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		code       string
+	}{
+		{"Malformed JSON", `{"url":`, http.StatusBadRequest, ErrCodeInvalidBody},
+		{"Missing URL", `{}`, http.StatusUnprocessableEntity, ErrCodeMissingURL},
+		{"Blank URL", `{"url": "   "}`, http.StatusUnprocessableEntity, ErrCodeMissingURL},
+		{"Invalid URL", `{"url": "invalid-url"}`, http.StatusUnprocessableEntity, ErrCodeInvalidURL},
+		{"Invalid link scope", `{"url": "https://example.com", "options": {"link_scope": "planet"}}`, http.StatusUnprocessableEntity, ErrCodeInvalidOptions},
+		{"CA bundle", `{"url": "https://example.com", "options": {"ca_bundle": "/etc/passwd"}}`, http.StatusUnprocessableEntity, ErrCodeOptionNotAllowed},
+		{"Chrome URL", `{"url": "https://example.com", "options": {"render": true, "chrome_url": "http://10.0.0.1:9222"}}`, http.StatusUnprocessableEntity, ErrCodeOptionNotAllowed},
+		{"Proxy", `{"url": "https://example.com", "options": {"proxy_url": "http://10.0.0.1:3128"}}`, http.StatusUnprocessableEntity, ErrCodeOptionNotAllowed},
+		{"Insecure TLS", `{"url": "https://example.com", "options": {"insecure_skip_verify": true}}`, http.StatusUnprocessableEntity, ErrCodeOptionNotAllowed},
+		{"Unknown analyzer", `{"url": "https://example.com", "options": {"disabled_analyzers": ["spelling"]}}`, http.StatusUnprocessableEntity, ErrCodeInvalidOptions},
+		{"Invalid timeout", `{"url": "https://example.com", "options": {"timeout": "soon"}}`, http.StatusBadRequest, ErrCodeInvalidBody},
+		{"Timeout too long", `{"url": "https://example.com", "options": {"timeout": "2h"}}`, http.StatusUnprocessableEntity, ErrCodeInvalidOptions},
+		{"Too many attempts", `{"url": "https://example.com", "options": {"max_attempts": 1000}}`, http.StatusUnprocessableEntity, ErrCodeInvalidOptions},
+		{"Retry delay too long", `{"url": "https://example.com", "options": {"retry_max_delay": 3600}}`, http.StatusUnprocessableEntity, ErrCodeInvalidOptions},
+		{"Too many redirects", `{"url": "https://example.com", "options": {"max_redirects": 500}}`, http.StatusUnprocessableEntity, ErrCodeInvalidOptions},
+		{"Headers too large", `{"url": "https://example.com", "options": {"headers": {"X-Big": "` + strings.Repeat("a", MaxRequestHeaderSize) + `"}}}`, http.StatusUnprocessableEntity, ErrCodeInvalidOptions},
+	}
+
+	router := setupTestRouter(newTestHandler(t))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(router, "/api/v1/crawl", tt.body)
+
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode error response: %v", err)
+			}
+			if resp.Error.Code != tt.code {
+				t.Errorf("Expected error code %q, got %q", tt.code, resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestBatchCrawlAPIHandler(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	router := setupTestRouter(newTestHandler(t))
+	body := fmt.Sprintf(`{"urls": [%q, %q]}`, server.URL, server.URL+"/missing")
+	w := postJSON(router, "/api/v1/crawl/batch", body)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp BatchCrawlResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(resp.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(resp.Results))
+	}
+	if !resp.Results[0].Success {
+		t.Errorf("Expected first crawl to succeed, got error: %s", resp.Results[0].Error)
+	}
+	if resp.Results[1].Success || resp.Results[1].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected second crawl to fail with 404, got %d", resp.Results[1].StatusCode)
+	}
+}
+
+func TestBatchCrawlAPIHandler_Errors(t *testing.T) {
+	tooMany := strings.Repeat(`"https://doruk.com",`, MaxBatchSize) + `"https://doruk.com"`
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		code       string
+		details    int
+	}{
+		{"Malformed JSON", `[]`, http.StatusBadRequest, ErrCodeInvalidBody, 0},
+		{"Empty batch", `{"urls": []}`, http.StatusUnprocessableEntity, ErrCodeEmptyBatch, 0},
+		{"Too many URLs", `{"urls": [` + tooMany + `]}`, http.StatusUnprocessableEntity, ErrCodeBatchTooLarge, 0},
+		{"Invalid URLs", `{"urls": ["https://doruk.com", "invalid-url", ""]}`, http.StatusUnprocessableEntity, ErrCodeValidationFailed, 2},
+	}
+
+	router := setupTestRouter(newTestHandler(t))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(router, "/api/v1/crawl/batch", tt.body)
+
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode error response: %v", err)
+			}
+			if resp.Error.Code != tt.code {
+				t.Errorf("Expected error code %q, got %q", tt.code, resp.Error.Code)
+			}
+			if len(resp.Error.Details) != tt.details {
+				t.Errorf("Expected %d error details, got %d", tt.details, len(resp.Error.Details))
+			}
+		})
+	}
+}
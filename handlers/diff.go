@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/diff"
+	"go-webcrawler/storage"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeMissingRecords   = "missing_records"
+	ErrCodeNotEnoughHistory = "not_enough_history"
+	ErrCodeURLMismatch      = "url_mismatch"
+)
+
+type DiffResponse struct {
+	FromID string    `json:"from_id"`
+	ToID   string    `json:"to_id"`
+	Diff   diff.Diff `json:"diff"`
+}
+
+func (h *Handler) Diff(c *gin.Context) {
+	from, to, _, apiErr := h.loadDiffRecords(c)
+	if apiErr != nil {
+		h.renderIndex(c, gin.H{
+			"error": apiErr.Message,
+		})
+		return
+	}
+
+	h.renderIndex(c, gin.H{
+		"diff":      diff.Results(from.Result, to.Result),
+		"diff_from": from,
+		"diff_to":   to,
+	})
+}
+
+func (h *Handler) DiffAPI(c *gin.Context) {
+	from, to, status, apiErr := h.loadDiffRecords(c)
+	if apiErr != nil {
+		abortWithAPIError(c, status, *apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, DiffResponse{
+		FromID: from.ID,
+		ToID:   to.ID,
+		Diff:   diff.Results(from.Result, to.Result),
+	})
+}
+
+// loadDiffRecords picks the records named by the from and to parameters, or
+// the two latest crawls of the url parameter
+func (h *Handler) loadDiffRecords(c *gin.Context) (storage.Record, storage.Record, int, *APIError) {
+	fromID := c.Query("from")
+	toID := c.Query("to")
+	url := strings.TrimSpace(c.Query("url"))
+
+	var from, to storage.Record
+
+	switch {
+	case fromID != "" && toID != "":
+		var err error
+		if from, err = h.Store.Get(fromID); err != nil {
+			status, apiErr := recordError(fromID, err)
+			return from, to, status, apiErr
+		}
+		if to, err = h.Store.Get(toID); err != nil {
+			status, apiErr := recordError(toID, err)
+			return from, to, status, apiErr
+		}
+	case url != "":
+		records, err := h.Store.Find(storage.Query{URL: crawler.NormalizeURL(url), Limit: 2})
+		if err != nil {
+			return from, to, http.StatusInternalServerError, &APIError{
+				Code:    ErrCodeStorage,
+				Message: fmt.Sprintf("Failed to load history: %v", err),
+			}
+		}
+		if len(records) < 2 {
+			return from, to, http.StatusNotFound, &APIError{
+				Code:    ErrCodeNotEnoughHistory,
+				Message: "This URL needs to be crawled at least twice before crawls can be compared",
+			}
+		}
+		from, to = records[1], records[0]
+	default:
+		return from, to, http.StatusUnprocessableEntity, &APIError{
+			Code:    ErrCodeMissingRecords,
+			Message: "Please provide either from and to record IDs or a URL",
+		}
+	}
+
+	if from.URL != to.URL {
+		return from, to, http.StatusUnprocessableEntity, &APIError{
+			Code:    ErrCodeURLMismatch,
+			Message: fmt.Sprintf("Only crawls of the same URL can be compared, got %s and %s", from.URL, to.URL),
+		}
+	}
+
+	return from, to, http.StatusOK, nil
+}
+
+func recordError(id string, err error) (int, *APIError) {
+	if errors.Is(err, storage.ErrNotFound) {
+		return http.StatusNotFound, &APIError{
+			Code:    ErrCodeRecordNotFound,
+			Message: fmt.Sprintf("Crawl record %s not found", id),
+		}
+	}
+
+	return http.StatusInternalServerError, &APIError{
+		Code:    ErrCodeStorage,
+		Message: fmt.Sprintf("Failed to load crawl record %s: %v", id, err),
+	}
+}
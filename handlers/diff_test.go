@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"go-webcrawler/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDiffTestHandler(t *testing.T) *Handler {
+	h := newTestHandler(t)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	h.Store.Save(models.CrawlResult{
+		URL:        "https://doruk.com/",
+		CrawledAt:  base,
+		StatusCode: 200,
+		Success:    true,
+		Title:      "Before Deploy",
+		Headings:   map[string]int{"h1": 1},
+		Links:      []models.Link{{URL: "https://doruk.com/old"}},
+	})
+	h.Store.Save(models.CrawlResult{
+		URL:        "https://doruk.com/",
+		CrawledAt:  base.Add(time.Hour),
+		StatusCode: 200,
+		Success:    true,
+		Title:      "After Deploy",
+		Headings:   map[string]int{"h1": 2},
+		Links:      []models.Link{{URL: "https://doruk.com/new"}},
+	})
+	h.Store.Save(models.CrawlResult{
+		URL:       "https://other.com",
+		CrawledAt: base,
+	})
+
+	return h
+}
+
+func TestDiffAPI(t *testing.T) {
+	router := setupTestRouter(newDiffTestHandler(t))
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"Latest crawls of URL", "/api/v1/diff?url=doruk.com"},
+		{"Record IDs", "/api/v1/diff?from=1&to=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp DiffResponse
+			w := getJSON(router, tt.path, &resp)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+			if resp.FromID != "1" || resp.ToID != "2" {
+				t.Errorf("Expected diff from 1 to 2, got %s to %s", resp.FromID, resp.ToID)
+			}
+			if resp.Diff.Title == nil || resp.Diff.Title.After != "After Deploy" {
+				t.Errorf("Expected title change, got %+v", resp.Diff.Title)
+			}
+			if len(resp.Diff.Headings) != 1 || resp.Diff.Headings[0].Delta != 1 {
+				t.Errorf("Expected h1 delta of 1, got %+v", resp.Diff.Headings)
+			}
+			if len(resp.Diff.LinksAdded) != 1 || len(resp.Diff.LinksRemoved) != 1 {
+				t.Errorf("Expected one added and one removed link, got %+v", resp.Diff)
+			}
+		})
+	}
+}
+
+func TestDiffAPI_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		code       string
+	}{
+		{"No parameters", "/api/v1/diff", http.StatusUnprocessableEntity, ErrCodeMissingRecords},
+		{"Single crawl", "/api/v1/diff?url=https://other.com", http.StatusNotFound, ErrCodeNotEnoughHistory},
+		{"Unknown record", "/api/v1/diff?from=1&to=42", http.StatusNotFound, ErrCodeRecordNotFound},
+		{"Different URLs", "/api/v1/diff?from=1&to=3", http.StatusUnprocessableEntity, ErrCodeURLMismatch},
+	}
+
+	router := setupTestRouter(newDiffTestHandler(t))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ErrorResponse
+			w := getJSON(router, tt.path, &resp)
+
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if resp.Error.Code != tt.code {
+				t.Errorf("Expected error code %q, got %q", tt.code, resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestDiffHandler(t *testing.T) {
+	router := setupTestRouter(newDiffTestHandler(t))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/diff?url=https://doruk.com", nil)
+	router.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, "Before Deploy") || !strings.Contains(body, "After Deploy") {
+		t.Error("Expected both titles side by side")
+	}
+	if !strings.Contains(body, `class="changed"`) {
+		t.Error("Expected changed rows to be highlighted")
+	}
+	if !strings.Contains(body, "https://doruk.com/new") {
+		t.Error("Expected added link in comparison")
+	}
+}
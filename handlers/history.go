@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/storage"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 1000
+)
+
+const (
+	ErrCodeInvalidTime    = "invalid_time"
+	ErrCodeInvalidLimit   = "invalid_limit"
+	ErrCodeRecordNotFound = "record_not_found"
+	ErrCodeStorage        = "storage_error"
+)
+
+type HistoryResponse struct {
+	Records []storage.Record `json:"records"`
+}
+
+func (h *Handler) History(c *gin.Context) {
+	textInput := strings.TrimSpace(c.Query("url"))
+	if textInput == "" {
+		c.HTML(http.StatusOK, "history.html", gin.H{})
+		return
+	}
+
+	query, fieldErr := parseHistoryQuery(c)
+	if fieldErr != nil {
+		c.HTML(http.StatusOK, "history.html", gin.H{
+			"error":       fieldErr.Message,
+			"input_value": textInput,
+		})
+		return
+	}
+
+	records, err := h.Store.Find(query)
+	if err != nil {
+		c.HTML(http.StatusOK, "history.html", gin.H{
+			"error":       fmt.Sprintf("Failed to load history: %v", err),
+			"input_value": textInput,
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "history.html", gin.H{
+		"url":         query.URL,
+		"records":     records,
+		"input_value": textInput,
+	})
+}
+
+func (h *Handler) HistoryAPI(c *gin.Context) {
+	query, fieldErr := parseHistoryQuery(c)
+	if fieldErr != nil {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, APIError{
+			Code:    fieldErr.Code,
+			Message: fieldErr.Message,
+			Details: []FieldError{*fieldErr},
+		})
+		return
+	}
+
+	records, err := h.Store.Find(query)
+	if err != nil {
+		abortWithAPIError(c, http.StatusInternalServerError, APIError{
+			Code:    ErrCodeStorage,
+			Message: fmt.Sprintf("Failed to load history: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, HistoryResponse{Records: records})
+}
+
+func (h *Handler) GetRecordAPI(c *gin.Context) {
+	record, err := h.Store.Get(c.Param("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithAPIError(c, http.StatusNotFound, APIError{
+			Code:    ErrCodeRecordNotFound,
+			Message: "Crawl record not found",
+		})
+		return
+	}
+	if err != nil {
+		abortWithAPIError(c, http.StatusInternalServerError, APIError{
+			Code:    ErrCodeStorage,
+			Message: fmt.Sprintf("Failed to load crawl record: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func parseHistoryQuery(c *gin.Context) (storage.Query, *FieldError) {
+	query := storage.Query{
+		Limit: DefaultHistoryLimit,
+	}
+
+	if url := strings.TrimSpace(c.Query("url")); url != "" {
+		query.URL = crawler.NormalizeURL(url)
+	}
+
+	var err error
+	if query.Since, err = parseHistoryTime(c.Query("since"), false); err != nil {
+		return query, invalidTimeError("since")
+	}
+	if query.Until, err = parseHistoryTime(c.Query("until"), true); err != nil {
+		return query, invalidTimeError("until")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > MaxHistoryLimit {
+			return query, &FieldError{
+				Field:   "limit",
+				Code:    ErrCodeInvalidLimit,
+				Message: fmt.Sprintf("Limit must be a number between 1 and %d", MaxHistoryLimit),
+			}
+		}
+	}
+
+	return query, nil
+}
+
+// parseHistoryTime accepts RFC 3339 timestamps or plain dates. A plain date used
+// as the end of a range includes the whole day.
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func invalidTimeError(field string) *FieldError {
+	return &FieldError{
+		Field:   field,
+		Code:    ErrCodeInvalidTime,
+		Message: fmt.Sprintf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", field),
+	}
+}
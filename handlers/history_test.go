@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"go-webcrawler/models"
+	"go-webcrawler/storage"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	router := setupTestRouter(newTestHandler(t))
+
+	for i := 0; i < 2; i++ {
+		w := postJSON(router, "/api/v1/crawl", fmt.Sprintf(`{"url": %q}`, server.URL))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+	}
+
+	var resp HistoryResponse
+	w := getJSON(router, "/api/v1/history?url="+url.QueryEscape(server.URL), &resp)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(resp.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(resp.Records))
+	}
+	if resp.Records[0].Result.Title != "API Page" {
+		t.Errorf("Expected stored title 'API Page', got %q", resp.Records[0].Result.Title)
+	}
+
+	var record storage.Record
+	w = getJSON(router, "/api/v1/history/"+resp.Records[0].ID, &record)
+	if w.Code != http.StatusOK || record.ID != resp.Records[0].ID {
+		t.Errorf("Expected record %s, got status %d", resp.Records[0].ID, w.Code)
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour).Format(time.DateOnly)
+	getJSON(router, "/api/v1/history?since="+tomorrow, &resp)
+	if len(resp.Records) != 0 {
+		t.Errorf("Expected no records since tomorrow, got %d", len(resp.Records))
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/history?url="+url.QueryEscape(server.URL), nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if strings.Count(w.Body.String(), "API Page") != 2 {
+		t.Error("Expected both crawls in history page")
+	}
+}
+
+func TestHistory_SubmitRecorded(t *testing.T) {
+	h := newTestHandler(t)
+	router := setupTestRouter(h)
+
+	form := url.Values{}
+	form.Add("text_input", "invalid-url")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/submit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+
+	records, _ := h.Store.Find(storage.Query{})
+	if len(records) != 0 {
+		t.Errorf("Expected invalid input not to be recorded, got %d records", len(records))
+	}
+
+	h.record(models.CrawlResult{URL: "https://doruk.com"})
+
+	records, _ = h.Store.Find(storage.Query{URL: "https://doruk.com"})
+	if len(records) != 1 {
+		t.Errorf("Expected 1 recorded crawl, got %d", len(records))
+	}
+}
+
+func TestHistoryAPI_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		code       string
+	}{
+		{"Invalid since", "/api/v1/history?since=yesterday", http.StatusUnprocessableEntity, ErrCodeInvalidTime},
+		{"Invalid until", "/api/v1/history?until=2025-13-01", http.StatusUnprocessableEntity, ErrCodeInvalidTime},
+		{"Invalid limit", "/api/v1/history?limit=0", http.StatusUnprocessableEntity, ErrCodeInvalidLimit},
+		{"Unknown record", "/api/v1/history/missing", http.StatusNotFound, ErrCodeRecordNotFound},
+	}
+
+	router := setupTestRouter(newTestHandler(t))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ErrorResponse
+			w := getJSON(router, tt.path, &resp)
+
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if resp.Error.Code != tt.code {
+				t.Errorf("Expected error code %q, got %q", tt.code, resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		endOfDay bool
+		expected time.Time
+	}{
+		{"Empty", "", false, time.Time{}},
+		{"RFC 3339", "2025-01-02T03:04:05Z", false, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"Start of day", "2025-01-02", false, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"End of day", "2025-01-02", true, time.Date(2025, 1, 2, 23, 59, 59, 999999999, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseHistoryTime(tt.value, tt.endOfDay)
+			if err != nil {
+				t.Fatalf("parseHistoryTime(%q) failed: %v", tt.value, err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("parseHistoryTime(%q) = %v; want %v", tt.value, result, tt.expected)
+			}
+		})
+	}
+}
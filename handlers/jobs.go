@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/jobs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// MaxJobDepth and MaxJobPages bound a job, whose pages all stay in memory until it is pruned
+	MaxJobDepth = 5
+	MaxJobPages = 500
+)
+
+const (
+	ErrCodeInvalidScope = "invalid_scope"
+	ErrCodeInvalidDepth = "invalid_depth"
+	ErrCodeInvalidPages = "invalid_max_pages"
+	ErrCodeJobNotFound  = "job_not_found"
+	ErrCodeJobFinished  = "job_finished"
+	ErrCodeQueueFull    = "queue_full"
+	ErrCodeUnavailable  = "unavailable"
+)
+
+type JobListResponse struct {
+	Jobs []jobs.Job `json:"jobs"`
+}
+
+func (h *Handler) SubmitJobAPI(c *gin.Context) {
+	var req jobs.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithAPIError(c, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidBody,
+			Message: fmt.Sprintf("Request body must be a JSON object: %v", err),
+		})
+		return
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	fieldErr := validateJobRequest(req)
+	if fieldErr == nil {
+		_, fieldErr = h.crawlerOptions(req.IgnoreRobots, req.Options)
+	}
+	if fieldErr != nil {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, APIError{
+			Code:    fieldErr.Code,
+			Message: fieldErr.Message,
+			Details: []FieldError{*fieldErr},
+		})
+		return
+	}
+
+	job, err := h.Jobs.Submit(req)
+	if err != nil {
+		abortWithJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (h *Handler) ListJobsAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, JobListResponse{Jobs: h.Jobs.List()})
+}
+
+func (h *Handler) GetJobAPI(c *gin.Context) {
+	job, err := h.Jobs.Get(c.Param("id"))
+	if err != nil {
+		abortWithJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *Handler) CancelJobAPI(c *gin.Context) {
+	job, err := h.Jobs.Cancel(c.Param("id"))
+	if err != nil {
+		abortWithJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *Handler) SubmitJob(c *gin.Context) {
+	req := jobs.Request{
+		URL:          strings.TrimSpace(c.PostForm("site_url")),
+		IgnoreRobots: c.PostForm("ignore_robots") != "",
+		Scope:        crawler.Scope(c.PostForm("scope")),
+	}
+	var fieldErr *FieldError
+	req.MaxDepth, fieldErr = formInt(c, "max_depth", ErrCodeInvalidDepth)
+	if fieldErr == nil {
+		req.MaxPages, fieldErr = formInt(c, "max_pages", ErrCodeInvalidPages)
+	}
+	if fieldErr == nil {
+		fieldErr = validateJobRequest(req)
+	}
+
+	if fieldErr != nil {
+		h.renderIndex(c, gin.H{
+			"site_error": fieldErr.Message,
+			"site_value": req.URL,
+		})
+		return
+	}
+
+	job, err := h.Jobs.Submit(req)
+	if err != nil {
+		h.renderIndex(c, gin.H{
+			"site_error": fmt.Sprintf("Could not start crawl: %v", err),
+			"site_value": req.URL,
+		})
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/jobs/"+job.ID)
+}
+
+func (h *Handler) ShowJob(c *gin.Context) {
+	job, err := h.Jobs.Get(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "job.html", gin.H{
+			"error": "Job not found",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "job.html", gin.H{
+		"job": job,
+	})
+}
+
+func (h *Handler) CancelJob(c *gin.Context) {
+	id := c.Param("id")
+	h.Jobs.Cancel(id)
+	c.Redirect(http.StatusSeeOther, "/jobs/"+id)
+}
+
+// formInt reads a number from the form, an empty field is 0
+func formInt(c *gin.Context, field, code string) (int, *FieldError) {
+	value := strings.TrimSpace(c.PostForm(field))
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &FieldError{
+			Field:   field,
+			Code:    code,
+			Message: fmt.Sprintf("%s must be a whole number, got %q", field, value),
+		}
+	}
+	return n, nil
+}
+
+func validateJobRequest(req jobs.Request) *FieldError {
+	if fieldErr := validateURL("url", req.URL); fieldErr != nil {
+		return fieldErr
+	}
+
+	if req.MaxDepth < 0 || req.MaxDepth > MaxJobDepth {
+		return &FieldError{
+			Field:   "max_depth",
+			Code:    ErrCodeInvalidDepth,
+			Message: fmt.Sprintf("max_depth must be between 0 and %d", MaxJobDepth),
+		}
+	}
+	// 0 uses the default of crawler.DefaultMaxPages
+	if req.MaxPages < 0 || req.MaxPages > MaxJobPages {
+		return &FieldError{
+			Field:   "max_pages",
+			Code:    ErrCodeInvalidPages,
+			Message: fmt.Sprintf("max_pages must be between 1 and %d", MaxJobPages),
+		}
+	}
+
+	switch req.Scope {
+	case "", crawler.ScopeHost, crawler.ScopeSubdomains, crawler.ScopeDomain, crawler.ScopePrefix:
+		return nil
+	default:
+		return &FieldError{
+			Field:   "scope",
+			Code:    ErrCodeInvalidScope,
+			Message: fmt.Sprintf("Scope must be one of %q, %q, %q or %q", crawler.ScopeHost, crawler.ScopeSubdomains, crawler.ScopeDomain, crawler.ScopePrefix),
+		}
+	}
+}
+
+func abortWithJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		abortWithAPIError(c, http.StatusNotFound, APIError{Code: ErrCodeJobNotFound, Message: "Job not found"})
+	case errors.Is(err, jobs.ErrFinished):
+		abortWithAPIError(c, http.StatusConflict, APIError{Code: ErrCodeJobFinished, Message: "Job has already finished"})
+	case errors.Is(err, jobs.ErrQueueFull):
+		abortWithAPIError(c, http.StatusServiceUnavailable, APIError{Code: ErrCodeQueueFull, Message: "Too many crawls are queued, try again later"})
+	default:
+		abortWithAPIError(c, http.StatusServiceUnavailable, APIError{Code: ErrCodeUnavailable, Message: err.Error()})
+	}
+}
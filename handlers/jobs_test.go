@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/jobs"
+	"go-webcrawler/storage"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func getJSON(router *gin.Engine, path string, v any) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	router.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), v)
+	return w
+}
+
+func TestJobsAPI(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	manager := jobs.NewManager(1, 10, nil, crawler.Options{})
+	defer manager.Shutdown()
+	router := setupTestRouter(New(storage.NewMemoryStore(), manager, crawler.Options{}))
+
+	w := postJSON(router, "/api/v1/jobs", fmt.Sprintf(`{"url": %q, "max_depth": 1}`, server.URL))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	var job jobs.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if job.ID == "" {
+		t.Fatal("Expected job ID in response")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !job.State.Finished() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		getJSON(router, "/api/v1/jobs/"+job.ID, &job)
+	}
+
+	if job.State != jobs.StateDone {
+		t.Fatalf("Expected job to be done, got %q", job.State)
+	}
+	if job.Result == nil || len(job.Result.Pages) != 1 || job.Result.Pages[0].Title != "API Page" {
+		t.Errorf("Expected crawl result with the test page, got %+v", job.Result)
+	}
+
+	// The test page has no <main>, which shows up in the summary
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/"+job.ID, nil)
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `<table class="accessibility"`) || !strings.Contains(w.Body.String(), "landmark-main") {
+		t.Error("Expected the accessibility summary in the job page")
+	}
+
+	var list JobListResponse
+	getJSON(router, "/api/v1/jobs", &list)
+	if len(list.Jobs) != 1 {
+		t.Errorf("Expected 1 job in list, got %d", len(list.Jobs))
+	}
+
+	w = postJSON(router, "/api/v1/jobs/"+job.ID+"/cancel", "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d when cancelling a finished job, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestJobsAPI_Errors(t *testing.T) {
+	manager := jobs.NewManager(0, 10, nil, crawler.Options{})
+	defer manager.Shutdown()
+	router := setupTestRouter(New(storage.NewMemoryStore(), manager, crawler.Options{}))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		code       string
+	}{
+		{"Malformed JSON", "POST", "/api/v1/jobs", `{`, http.StatusBadRequest, ErrCodeInvalidBody},
+		{"Invalid URL", "POST", "/api/v1/jobs", `{"url": "invalid-url"}`, http.StatusUnprocessableEntity, ErrCodeInvalidURL},
+		{"Invalid scope", "POST", "/api/v1/jobs", `{"url": "https://doruk.com", "scope": "planet"}`, http.StatusUnprocessableEntity, ErrCodeInvalidScope},
+		{"Depth too large", "POST", "/api/v1/jobs", `{"url": "https://doruk.com", "max_depth": 100}`, http.StatusUnprocessableEntity, ErrCodeInvalidDepth},
+		{"Negative depth", "POST", "/api/v1/jobs", `{"url": "https://doruk.com", "max_depth": -1}`, http.StatusUnprocessableEntity, ErrCodeInvalidDepth},
+		{"Too many pages", "POST", "/api/v1/jobs", `{"url": "https://doruk.com", "max_pages": 100000}`, http.StatusUnprocessableEntity, ErrCodeInvalidPages},
+		{"Unknown job", "GET", "/api/v1/jobs/missing", "", http.StatusNotFound, ErrCodeJobNotFound},
+		{"Cancel unknown job", "POST", "/api/v1/jobs/missing/cancel", "", http.StatusNotFound, ErrCodeJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode error response: %v", err)
+			}
+			if resp.Error.Code != tt.code {
+				t.Errorf("Expected error code %q, got %q", tt.code, resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestJobsWeb(t *testing.T) {
+	// No workers, so the job stays queued and can be cancelled
+	manager := jobs.NewManager(0, 10, nil, crawler.Options{})
+	defer manager.Shutdown()
+	router := setupTestRouter(New(storage.NewMemoryStore(), manager, crawler.Options{}))
+
+	form := url.Values{}
+	form.Add("site_url", "https://doruk.com")
+	form.Add("max_depth", "1")
+	form.Add("max_pages", "10")
+	form.Add("scope", "host")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status code %d, got %d", http.StatusSeeOther, w.Code)
+	}
+	location := w.Header().Get("Location")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", location, nil)
+	router.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), "queued") {
+		t.Error("Expected queued state in job page")
+	}
+	if !strings.Contains(w.Body.String(), `http-equiv="refresh"`) {
+		t.Error("Expected unfinished job page to refresh")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", location+"/cancel", nil)
+	router.ServeHTTP(w, req)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", location, nil)
+	router.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), "cancelled") {
+		t.Error("Expected cancelled state in job page")
+	}
+}
+
+func TestJobsWeb_InvalidURL(t *testing.T) {
+	manager := jobs.NewManager(0, 10, nil, crawler.Options{})
+	defer manager.Shutdown()
+	router := setupTestRouter(New(storage.NewMemoryStore(), manager, crawler.Options{}))
+
+	form := url.Values{}
+	form.Add("site_url", "invalid-url")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Please enter a valid URL") {
+		t.Error("Expected invalid URL error message in response")
+	}
+}
+
+func TestJobsWeb_InvalidLimits(t *testing.T) {
+	manager := jobs.NewManager(0, 10, nil, crawler.Options{})
+	defer manager.Shutdown()
+	router := setupTestRouter(New(storage.NewMemoryStore(), manager, crawler.Options{}))
+
+	tests := []struct {
+		name     string
+		field    string
+		value    string
+		expected string
+	}{
+		{"Pages not a number", "max_pages", "abc", "max_pages must be a whole number"},
+		{"Depth not a number", "max_depth", "2.5", "max_depth must be a whole number"},
+		{"Depth too large", "max_depth", "50", "max_depth must be between 0 and 5"},
+		{"Too many pages", "max_pages", "100000", "max_pages must be between 1 and 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("site_url", "https://doruk.com")
+			form.Add(tt.field, tt.value)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expected) {
+				t.Errorf("Expected %q in response", tt.expected)
+			}
+		})
+	}
+
+	if jobs := manager.List(); len(jobs) != 0 {
+		t.Errorf("Expected no job to be queued, got %d", len(jobs))
+	}
+}
@@ -3,28 +3,47 @@ package handlers
 import (
 	"fmt"
 	"go-webcrawler/crawler"
+	"go-webcrawler/jobs"
+	"go-webcrawler/models"
+	"go-webcrawler/storage"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func IndexHandler(c *gin.Context) {
-	c.HTML(http.StatusOK, "index.html", gin.H{})
+type Handler struct {
+	Store storage.Store
+	Jobs  *jobs.Manager
+	// Options are the crawler options every request starts from
+	Options crawler.Options
 }
 
-func SubmitHandler(c *gin.Context) {
+func New(store storage.Store, manager *jobs.Manager, opts crawler.Options) *Handler {
+	return &Handler{
+		Store:   store,
+		Jobs:    manager,
+		Options: opts,
+	}
+}
+
+func (h *Handler) Index(c *gin.Context) {
+	h.renderIndex(c, gin.H{})
+}
+
+func (h *Handler) Submit(c *gin.Context) {
 	textInput := strings.TrimSpace(c.PostForm("text_input"))
 
 	if textInput == "" {
-		c.HTML(http.StatusOK, "index.html", gin.H{
+		h.renderIndex(c, gin.H{
 			"error": "Please enter a URL!",
 		})
 		return
 	}
 
 	if !crawler.IsValidURL(textInput) {
-		c.HTML(http.StatusOK, "index.html", gin.H{
+		h.renderIndex(c, gin.H{
 			"error":       "Please enter a valid URL (must start with http:// or https://)",
 			"input_value": textInput,
 		})
@@ -33,9 +52,39 @@ func SubmitHandler(c *gin.Context) {
 
 	fmt.Printf("WebCrawler processing URL: %s\n", textInput)
 
-	result := crawler.CrawlURL(textInput)
+	ignoreRobots := c.PostForm("ignore_robots") != ""
+	render := c.PostForm("render") != ""
+	disabled := c.PostFormArray("disable_analyzer")
+	opts := h.Options
+	if ignoreRobots {
+		opts.IgnoreRobots = true
+	}
+	if render {
+		opts.Render = true
+	}
+	if len(disabled) > 0 {
+		opts.DisabledAnalyzers = append(slices.Clone(opts.DisabledAnalyzers), disabled...)
+	}
+
+	result := crawler.CrawlURLWithOptions(c.Request.Context(), textInput, opts)
+	h.record(result)
 
-	c.HTML(http.StatusOK, "index.html", gin.H{
-		"result": result,
+	h.renderIndex(c, gin.H{
+		"result":             result,
+		"ignore_robots":      ignoreRobots,
+		"render":             render,
+		"disabled_analyzers": disabled,
 	})
 }
+
+// renderIndex renders the start page, which always lists the analyzers that can be turned off
+func (h *Handler) renderIndex(c *gin.Context, data gin.H) {
+	data["analyzers"] = h.Options.AnalyzerNames()
+	c.HTML(http.StatusOK, "index.html", data)
+}
+
+func (h *Handler) record(result models.CrawlResult) {
+	if _, err := h.Store.Save(result); err != nil {
+		fmt.Printf("WebCrawler failed to store result for %s: %v\n", result.URL, err)
+	}
+}
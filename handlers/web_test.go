@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/jobs"
+	"go-webcrawler/storage"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -10,19 +14,41 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func setupTestRouter() *gin.Engine {
+func newTestHandler(t *testing.T) *Handler {
+	manager := jobs.NewManager(1, 10, nil, crawler.Options{})
+	t.Cleanup(manager.Shutdown)
+	return New(storage.NewMemoryStore(), manager, crawler.Options{})
+}
+
+func setupTestRouter(h *Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	router.LoadHTMLGlob("../templates/*")
 
-	router.GET("/", IndexHandler)
-	router.POST("/submit", SubmitHandler)
+	router.GET("/", h.Index)
+	router.POST("/submit", h.Submit)
+	router.GET("/history", h.History)
+	router.GET("/diff", h.Diff)
+	router.POST("/jobs", h.SubmitJob)
+	router.GET("/jobs/:id", h.ShowJob)
+	router.POST("/jobs/:id/cancel", h.CancelJob)
+
+	api := router.Group("/api/v1")
+	api.POST("/crawl", h.CrawlAPI)
+	api.POST("/crawl/batch", h.BatchCrawlAPI)
+	api.GET("/history", h.HistoryAPI)
+	api.GET("/history/:id", h.GetRecordAPI)
+	api.GET("/diff", h.DiffAPI)
+	api.POST("/jobs", h.SubmitJobAPI)
+	api.GET("/jobs", h.ListJobsAPI)
+	api.GET("/jobs/:id", h.GetJobAPI)
+	api.POST("/jobs/:id/cancel", h.CancelJobAPI)
 
 	return router
 }
 
 func TestIndexHandler(t *testing.T) {
-	router := setupTestRouter()
+	router := setupTestRouter(newTestHandler(t))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/", nil)
@@ -34,7 +60,7 @@ func TestIndexHandler(t *testing.T) {
 }
 
 func TestSubmitHandler_EmptyInput(t *testing.T) {
-	router := setupTestRouter()
+	router := setupTestRouter(newTestHandler(t))
 
 	form := url.Values{}
 	form.Add("text_input", "")
@@ -54,7 +80,7 @@ func TestSubmitHandler_EmptyInput(t *testing.T) {
 }
 
 func TestSubmitHandler_InvalidURL(t *testing.T) {
-	router := setupTestRouter()
+	router := setupTestRouter(newTestHandler(t))
 
 	form := url.Values{}
 	form.Add("text_input", "invalid-url")
@@ -74,7 +100,7 @@ func TestSubmitHandler_InvalidURL(t *testing.T) {
 }
 
 func TestSubmitHandler_ValidURL(t *testing.T) {
-	router := setupTestRouter()
+	router := setupTestRouter(newTestHandler(t))
 
 	form := url.Values{}
 	form.Add("text_input", "https://doruk.com")
@@ -88,3 +114,149 @@ func TestSubmitHandler_ValidURL(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 }
+
+// submitPage submits a server serving page through the web form and returns the response body
+func submitPage(t *testing.T, page string) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, page)
+	}))
+	t.Cleanup(server.Close)
+
+	router := setupTestRouter(newTestHandler(t))
+
+	form := url.Values{}
+	form.Add("text_input", server.URL)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/submit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	return w.Body.String()
+}
+
+func expectInBody(t *testing.T, body string, expected ...string) {
+	t.Helper()
+	for _, want := range expected {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in response", want)
+		}
+	}
+}
+
+func TestSubmitHandler_LinksTable(t *testing.T) {
+	body := submitPage(t, `<html><body>
+		<a href="/about" rel="nofollow">About us</a>
+		<a href="tel:05551234567">Call</a>
+	</body></html>`)
+
+	expectInBody(t, body, `<table class="links"`, "About us", "nofollow", "tel:05551234567", `data-category="phone"`)
+}
+
+func TestSubmitHandler_Forms(t *testing.T) {
+	body := submitPage(t, `<html><body>
+		<form method="post" action="http://other.example/login"><input type="password" name="pw"></form>
+	</body></html>`)
+
+	expectInBody(t, body, `<table class="forms"`, "pw (password)", "Password over http")
+}
+
+func TestSubmitHandler_Metadata(t *testing.T) {
+	body := submitPage(t, `<html><head><title>No canonical</title></head><body></body></html>`)
+
+	expectInBody(t, body, `class="metadata"`, "No canonical link")
+}
+
+func TestSubmitHandler_Outline(t *testing.T) {
+	body := submitPage(t, `<html><body><h2>Section</h2></body></html>`)
+
+	expectInBody(t, body, `class="outline-issues"`, "missing_h1")
+}
+
+func TestSubmitHandler_StructuredData(t *testing.T) {
+	body := submitPage(t, `<html><body>
+		<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Organization"}</script>
+	</body></html>`)
+
+	expectInBody(t, body, "types: Organization")
+}
+
+func TestSubmitHandler_Accessibility(t *testing.T) {
+	body := submitPage(t, `<html><body><img src="logo.png"></body></html>`)
+
+	expectInBody(t, body, `<table class="accessibility"`, "landmark-main", "image-alt")
+}
+
+func TestSubmitHandler_DisableAnalyzers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Plain</title></head><body><img src="a.png"></body></html>`)
+	}))
+	defer server.Close()
+
+	router := setupTestRouter(newTestHandler(t))
+
+	form := url.Values{}
+	form.Add("text_input", server.URL)
+	form.Add("disable_analyzer", crawler.AnalyzerAccessibility)
+	form.Add("disable_analyzer", crawler.AnalyzerLinks)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/submit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, "Plain") || !strings.Contains(body, `<table class="findings"`) {
+		t.Error("Expected the title and the metadata findings in the response")
+	}
+	if strings.Contains(body, `<table class="accessibility"`) || strings.Contains(body, `<table class="links"`) {
+		t.Error("Expected the accessibility and links analyzers to be skipped")
+	}
+	if !strings.Contains(body, `value="accessibility" checked`) || !strings.Contains(body, `value="metadata" >`) {
+		t.Error("Expected the skipped analyzers to stay checked")
+	}
+}
+
+func TestSubmitHandler_IgnoreRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /")
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>Own Site</title></head></html>`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		ignoreRobots bool
+		expected     string
+	}{
+		{"Obeys robots.txt", false, "Blocked by robots.txt"},
+		{"Ignores robots.txt", true, "Successfully crawled!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(newTestHandler(t))
+
+			form := url.Values{}
+			form.Add("text_input", server.URL)
+			if tt.ignoreRobots {
+				form.Add("ignore_robots", "1")
+			}
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/submit", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router.ServeHTTP(w, req)
+
+			if !strings.Contains(w.Body.String(), tt.expected) {
+				t.Errorf("Expected %q in response", tt.expected)
+			}
+		})
+	}
+}
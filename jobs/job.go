@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"go-webcrawler/crawler"
+	"go-webcrawler/models"
+	"time"
+)
+
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateDone      State = "done"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+func (s State) Finished() bool {
+	return s == StateDone || s == StateFailed || s == StateCancelled
+}
+
+type Request struct {
+	URL          string        `json:"url"`
+	IgnoreRobots bool          `json:"ignore_robots"`
+	MaxDepth     int           `json:"max_depth"`
+	MaxPages     int           `json:"max_pages"`
+	Scope        crawler.Scope `json:"scope"`
+	// Options overrides the manager's crawler options for this job
+	Options *crawler.Overrides `json:"options,omitempty"`
+}
+
+// CrawlerOptions applies the request's overrides to base
+func (r Request) CrawlerOptions(base crawler.Options) crawler.Options {
+	opts := base
+	if r.Options != nil {
+		opts = opts.Apply(*r.Options)
+	}
+	if r.IgnoreRobots {
+		opts.IgnoreRobots = true
+	}
+	return opts
+}
+
+type Job struct {
+	ID         string             `json:"id"`
+	State      State              `json:"state"`
+	Request    Request            `json:"request"`
+	Result     *models.SiteResult `json:"result,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	StartedAt  *time.Time         `json:"started_at,omitempty"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/models"
+	"go-webcrawler/storage"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 100
+	MaxRetainedJobs  = 1000
+)
+
+var (
+	ErrQueueFull = errors.New("job queue is full")
+	ErrNotFound  = errors.New("job not found")
+	ErrFinished  = errors.New("job has already finished")
+	ErrShutdown  = errors.New("job manager is shut down")
+)
+
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+}
+
+type Manager struct {
+	queue chan string
+	wg    sync.WaitGroup
+	// store receives every crawled page, it may be nil
+	store   storage.Store
+	options crawler.Options
+
+	mu     sync.Mutex
+	jobs   map[string]*entry
+	order  []string
+	closed bool
+}
+
+func NewManager(workers, queueSize int, store storage.Store, opts crawler.Options) *Manager {
+	m := &Manager{
+		queue:   make(chan string, queueSize),
+		store:   store,
+		options: opts,
+		jobs:    make(map[string]*entry),
+	}
+
+	for i := 0; i < workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+
+	return m
+}
+
+func (m *Manager) Submit(req Request) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return Job{}, ErrShutdown
+	}
+
+	e := &entry{
+		job: Job{
+			ID:        newID(),
+			State:     StateQueued,
+			Request:   req,
+			CreatedAt: time.Now(),
+		},
+	}
+
+	select {
+	case m.queue <- e.job.ID:
+	default:
+		return Job{}, ErrQueueFull
+	}
+
+	m.jobs[e.job.ID] = e
+	m.order = append(m.order, e.job.ID)
+	m.prune()
+
+	return e.job, nil
+}
+
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return e.job, nil
+}
+
+// List returns all retained jobs, newest first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, m.jobs[m.order[i]].job)
+	}
+	return jobs
+}
+
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+
+	switch e.job.State {
+	case StateQueued:
+		// The worker skips it once it comes off the queue
+		m.finish(e, StateCancelled)
+	case StateRunning:
+		// The worker records the partial result once the crawl returns
+		e.job.State = StateCancelled
+		e.cancel()
+	default:
+		return e.job, ErrFinished
+	}
+
+	return e.job, nil
+}
+
+// Shutdown stops accepting jobs, cancels running ones and waits for the workers to exit
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.queue)
+	for _, e := range m.jobs {
+		if e.job.State == StateQueued {
+			m.finish(e, StateCancelled)
+		} else if e.job.State == StateRunning {
+			e.job.State = StateCancelled
+			e.cancel()
+		}
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+func (m *Manager) worker() {
+	defer m.wg.Done()
+
+	for id := range m.queue {
+		m.run(id)
+	}
+}
+
+func (m *Manager) run(id string) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	if !ok || e.job.State != StateQueued {
+		m.mu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	e.job.State = StateRunning
+	e.job.StartedAt = &now
+	e.cancel = cancel
+	req := e.job.Request
+	m.mu.Unlock()
+
+	result := crawler.CrawlSite(ctx, req.URL, crawler.SiteOptions{
+		Options:  req.CrawlerOptions(m.options),
+		MaxDepth: req.MaxDepth,
+		MaxPages: req.MaxPages,
+		Scope:    req.Scope,
+	})
+	m.record(result.Pages)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e.job.Result = &result
+	switch {
+	case e.job.State == StateCancelled:
+		m.finish(e, StateCancelled)
+	case result.Summary.PagesCrawled == 0:
+		if len(result.Pages) > 0 {
+			e.job.Error = result.Pages[0].Error
+		}
+		m.finish(e, StateFailed)
+	default:
+		m.finish(e, StateDone)
+	}
+}
+
+func (m *Manager) record(pages []models.CrawlResult) {
+	if m.store == nil {
+		return
+	}
+
+	for _, page := range pages {
+		if _, err := m.store.Save(page); err != nil {
+			fmt.Printf("WebCrawler failed to store result for %s: %v\n", page.URL, err)
+		}
+	}
+}
+
+func (m *Manager) finish(e *entry, state State) {
+	now := time.Now()
+	e.job.State = state
+	e.job.FinishedAt = &now
+}
+
+// prune drops the oldest finished jobs once more than MaxRetainedJobs are kept
+func (m *Manager) prune() {
+	excess := len(m.order) - MaxRetainedJobs
+	if excess <= 0 {
+		return
+	}
+
+	kept := m.order[:0]
+	for _, id := range m.order {
+		if excess > 0 && m.jobs[id].job.State.Finished() {
+			delete(m.jobs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
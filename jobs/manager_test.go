@@ -0,0 +1,172 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"go-webcrawler/crawler"
+	"go-webcrawler/storage"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func waitForState(t *testing.T, m *Manager, id string, states ...State) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", id, err)
+		}
+		for _, state := range states {
+			if job.State == state {
+				return job
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Job %s did not reach %v in time", id, states)
+	return Job{}
+}
+
+func TestManager_Done(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Job Page</title></head><body><a href="/other">Other</a></body></html>`)
+	}))
+	defer server.Close()
+
+	m := NewManager(2, 10, nil, crawler.Options{})
+	defer m.Shutdown()
+
+	job, err := m.Submit(Request{URL: server.URL, MaxDepth: 1, MaxPages: 5})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.ID == "" {
+		t.Fatal("Expected job to get an ID")
+	}
+	if job.State != StateQueued {
+		t.Errorf("Expected new job to be queued, got %q", job.State)
+	}
+
+	job = waitForState(t, m, job.ID, StateDone)
+
+	if job.Result == nil {
+		t.Fatal("Expected finished job to have a result")
+	}
+	if job.Result.Summary.PagesCrawled != 2 {
+		t.Errorf("Expected 2 crawled pages, got %d", job.Result.Summary.PagesCrawled)
+	}
+	if job.StartedAt == nil || job.FinishedAt == nil {
+		t.Error("Expected start and finish times to be recorded")
+	}
+}
+
+func TestManager_RecordsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><a href="/other">Other</a></body></html>`)
+	}))
+	defer server.Close()
+
+	store := storage.NewMemoryStore()
+	m := NewManager(1, 10, store, crawler.Options{})
+	defer m.Shutdown()
+
+	job, _ := m.Submit(Request{URL: server.URL, MaxDepth: 1})
+	waitForState(t, m, job.ID, StateDone)
+
+	records, err := store.Find(storage.Query{})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Expected 2 stored pages, got %d", len(records))
+	}
+}
+
+func TestManager_Failed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	m := NewManager(1, 10, nil, crawler.Options{})
+	defer m.Shutdown()
+
+	job, _ := m.Submit(Request{URL: server.URL})
+	job = waitForState(t, m, job.ID, StateFailed)
+
+	if job.Error == "" {
+		t.Error("Expected failed job to have an error message")
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	m := NewManager(1, 10, nil, crawler.Options{})
+	defer m.Shutdown()
+
+	running, _ := m.Submit(Request{URL: server.URL})
+	queued, _ := m.Submit(Request{URL: server.URL})
+
+	waitForState(t, m, running.ID, StateRunning)
+
+	if job, err := m.Cancel(queued.ID); err != nil || job.State != StateCancelled {
+		t.Errorf("Expected queued job to be cancelled, got %q (%v)", job.State, err)
+	}
+
+	if _, err := m.Cancel(running.ID); err != nil {
+		t.Errorf("Cancel of running job failed: %v", err)
+	}
+
+	job := waitForState(t, m, running.ID, StateCancelled)
+	deadline := time.Now().Add(5 * time.Second)
+	for job.FinishedAt == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		job, _ = m.Get(running.ID)
+	}
+	if job.FinishedAt == nil {
+		t.Error("Expected cancelled job to finish")
+	}
+
+	if _, err := m.Cancel(running.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Expected ErrFinished for finished job, got %v", err)
+	}
+}
+
+func TestManager_Errors(t *testing.T) {
+	m := NewManager(0, 1, nil, crawler.Options{})
+
+	if _, err := m.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := m.Submit(Request{URL: "https://doruk.com"}); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if _, err := m.Submit(Request{URL: "https://doruk.com"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+
+	m.Shutdown()
+
+	if _, err := m.Submit(Request{URL: "https://doruk.com"}); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected ErrShutdown, got %v", err)
+	}
+
+	if jobs := m.List(); len(jobs) != 1 || jobs[0].State != StateCancelled {
+		t.Errorf("Expected queued job to be cancelled on shutdown, got %+v", jobs)
+	}
+}
@@ -1,19 +1,109 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"go-webcrawler/cli"
+	"go-webcrawler/crawler"
 	"go-webcrawler/handlers"
+	"go-webcrawler/jobs"
+	"go-webcrawler/storage"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	DatabasePath = "crawls.db"
+	// ShutdownTimeout is how long open requests get to finish on SIGINT or SIGTERM
+	ShutdownTimeout = 10 * time.Second
+)
+
 func main() {
+	if len(os.Args) > 1 {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		code := cli.Run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
+	serve()
+}
+
+func serve() {
+	opts, err := crawler.LoadOptions(os.Getenv(crawler.ConfigEnv))
+	if err != nil {
+		log.Fatalf("Failed to load crawler options: %v", err)
+	}
+	// Every crawl of the server, whether from the web form, the API or a job, shares the host limits
+	opts.Limiter = crawler.NewHostLimiter(opts)
+
+	store, err := storage.OpenBoltStore(DatabasePath)
+	if err != nil {
+		log.Fatalf("Failed to open database %s: %v", DatabasePath, err)
+	}
+	defer store.Close()
+	if err := store.Migrate(crawler.NormalizeURL); err != nil {
+		log.Fatalf("Failed to migrate database %s: %v", DatabasePath, err)
+	}
+
+	manager := jobs.NewManager(jobs.DefaultWorkers, jobs.DefaultQueueSize, store, opts)
+	// Runs before the store is closed, so cancelled jobs still save their pages
+	defer manager.Shutdown()
+
+	h := handlers.New(store, manager, opts)
+
 	r := gin.Default()
 
 	r.LoadHTMLGlob("templates/*")
 	r.Static("/static", "./static")
 
-	r.GET("/", handlers.IndexHandler)
-	r.POST("/submit", handlers.SubmitHandler)
+	r.GET("/", h.Index)
+	r.POST("/submit", h.Submit)
+	r.GET("/history", h.History)
+	r.GET("/diff", h.Diff)
+	r.POST("/jobs", h.SubmitJob)
+	r.GET("/jobs/:id", h.ShowJob)
+	r.POST("/jobs/:id/cancel", h.CancelJob)
+
+	api := r.Group("/api/v1")
+	api.POST("/crawl", h.CrawlAPI)
+	api.POST("/crawl/batch", h.BatchCrawlAPI)
+	api.GET("/history", h.HistoryAPI)
+	api.GET("/history/:id", h.GetRecordAPI)
+	api.GET("/diff", h.DiffAPI)
+	api.POST("/jobs", h.SubmitJobAPI)
+	api.GET("/jobs", h.ListJobsAPI)
+	api.GET("/jobs/:id", h.GetJobAPI)
+	api.POST("/jobs/:id/cancel", h.CancelJobAPI)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: ":8080", Handler: r}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Server stopped: %v", err)
+		}
+		return
+	case <-ctx.Done():
+	}
 
-	r.Run(":8080")
+	log.Println("Shutting down, waiting for open requests and running jobs")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down the server cleanly: %v", err)
+	}
 }
@@ -0,0 +1,33 @@
+package models
+
+// Severity is how much a finding gets in the way, from critical (blocks
+// some users entirely) to minor (an annoyance)
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeveritySerious  Severity = "serious"
+	SeverityModerate Severity = "moderate"
+	SeverityMinor    Severity = "minor"
+)
+
+// AccessibilityFinding is an element that breaks an accessibility rule
+type AccessibilityFinding struct {
+	// Rule is the check's id, like "image-alt"
+	Rule string `json:"rule"`
+	// WCAG are the WCAG 2.1 success criteria the rule checks, like "1.1.1"
+	WCAG     []string `json:"wcag"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Path is empty for findings about the whole page, like a missing main landmark
+	Path string `json:"path,omitempty"`
+}
+
+// AccessibilityRuleSummary counts one rule's findings across a site crawl
+type AccessibilityRuleSummary struct {
+	Rule     string   `json:"rule"`
+	WCAG     []string `json:"wcag"`
+	Severity Severity `json:"severity"`
+	Findings int      `json:"findings"`
+	Pages    int      `json:"pages"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// Attempt is one try at fetching a URL, a retried request has several
+type Attempt struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	// WaitMS is the backoff before the next attempt, zero for the last one
+	WaitMS int64 `json:"wait_ms,omitempty"`
+}
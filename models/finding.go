@@ -0,0 +1,12 @@
+package models
+
+// Finding is a problem an analyzer found on a page
+type Finding struct {
+	// Analyzer is the name of the analyzer that reported it
+	Analyzer string   `json:"analyzer"`
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Path is the element the finding is about, empty for the whole page
+	Path string `json:"path,omitempty"`
+}
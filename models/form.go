@@ -0,0 +1,31 @@
+package models
+
+// Form is one <form> on a page
+type Form struct {
+	// Method is GET, POST or DIALOG, GET when the form doesn't say
+	Method string `json:"method"`
+	// Action is the resolved URL the form submits to, the page itself when the form has no action
+	Action  string      `json:"action"`
+	Enctype string      `json:"enctype"`
+	Inputs  []FormInput `json:"inputs"`
+	// CSRFField is the hidden input that looks like a CSRF token
+	CSRFField string `json:"csrf_field,omitempty"`
+	// CrossOrigin is set when the form submits to another scheme, host or port than the page
+	CrossOrigin bool `json:"cross_origin"`
+	// InsecurePassword is set when a password would be sent over plain http
+	InsecurePassword bool   `json:"insecure_password"`
+	Path             string `json:"path,omitempty"`
+}
+
+// FormInput is an input, select, textarea or named button of a form
+type FormInput struct {
+	Name string `json:"name,omitempty"`
+	// Type is the input type like "email", or "select", "textarea" or "button"
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// HasCSRFToken reports whether the form has a hidden input that looks like a CSRF token
+func (f Form) HasCSRFToken() bool {
+	return f.CSRFField != ""
+}
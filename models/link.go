@@ -0,0 +1,104 @@
+package models
+
+type LinkStatus string
+
+const (
+	LinkOK           LinkStatus = "ok"
+	LinkRedirect     LinkStatus = "redirect"
+	LinkClientError  LinkStatus = "4xx"
+	LinkServerError  LinkStatus = "5xx"
+	LinkTimeout      LinkStatus = "timeout"
+	LinkDNSError     LinkStatus = "dns_failure"
+	LinkTLSError     LinkStatus = "tls_error"
+	LinkNetworkError LinkStatus = "network_error"
+	// LinkBlocked links are disallowed by robots.txt and were not probed
+	LinkBlocked LinkStatus = "blocked"
+)
+
+// LinkCategory tells web links on the same site and elsewhere apart
+// from links that can't be crawled, like phone numbers or scripts
+type LinkCategory string
+
+const (
+	CategoryInternal   LinkCategory = "internal"
+	CategoryExternal   LinkCategory = "external"
+	CategoryEmail      LinkCategory = "email"
+	CategoryPhone      LinkCategory = "phone"
+	CategoryJavaScript LinkCategory = "javascript"
+	CategoryData       LinkCategory = "data"
+	CategoryFTP        LinkCategory = "ftp"
+	CategoryOther      LinkCategory = "other"
+)
+
+// ResourceType is what a link loads, a page to navigate to or an asset of the page
+type ResourceType string
+
+const (
+	ResourcePage       ResourceType = "page"
+	ResourceStylesheet ResourceType = "stylesheet"
+	ResourceScript     ResourceType = "script"
+	ResourceImage      ResourceType = "image"
+	ResourceIcon       ResourceType = "icon"
+	ResourceFont       ResourceType = "font"
+	ResourceFrame      ResourceType = "frame"
+	ResourceMedia      ResourceType = "media"
+	ResourceForm       ResourceType = "form"
+	// ResourceStyle is a url() in a style attribute, usually a background image
+	ResourceStyle ResourceType = "style"
+	// ResourceLink is any other <link>, like a canonical URL, feed or preconnect
+	ResourceLink ResourceType = "link"
+)
+
+// Link is one link on a page. Links found more than once appear once per
+// occurrence and share the status of a single check.
+type Link struct {
+	// Href is the attribute as written in the page
+	Href string `json:"href,omitempty"`
+	// URL is the canonical resolved URL, or the href itself for links that aren't http(s)
+	URL      string       `json:"url"`
+	Text     string       `json:"text,omitempty"`
+	Rel      []string     `json:"rel,omitempty"`
+	Target   string       `json:"target,omitempty"`
+	Category LinkCategory `json:"category,omitempty"`
+	Resource ResourceType `json:"resource,omitempty"`
+	// Element and Attribute are where the link was found, like "img" and "srcset"
+	Element   string `json:"element,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+	// Position counts links in document order, starting at 1
+	Position int `json:"position,omitempty"`
+	// Path locates the element in the DOM, like "html > body > nav > a:nth-of-type(2)"
+	Path string `json:"path,omitempty"`
+	// MixedContent is set for assets and form actions loaded over http by an https page
+	MixedContent bool       `json:"mixed_content,omitempty"`
+	StatusCode   int        `json:"status_code,omitempty"`
+	Status       LinkStatus `json:"status,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+func (l Link) Accessible() bool {
+	return l.Status == LinkOK || l.Status == LinkRedirect
+}
+
+// Web reports whether the link is an http(s) link. Links stored without a
+// category are from before other schemes were kept.
+func (l Link) Web() bool {
+	return l.Category == CategoryInternal || l.Category == CategoryExternal || l.Category == ""
+}
+
+// Navigation reports whether the link leads to another page. Links stored
+// without a resource type are from before assets were extracted.
+func (l Link) Navigation() bool {
+	return l.Resource == ResourcePage || l.Resource == ""
+}
+
+// Checked reports whether the link is probed. Form actions aren't, most of
+// them only accept a POST.
+func (l Link) Checked() bool {
+	return l.Web() && l.Resource != ResourceForm
+}
+
+// Broken reports whether a checked link failed its check or was never checked.
+// Links blocked by robots.txt are neither broken nor accessible.
+func (l Link) Broken() bool {
+	return l.Checked() && !l.Accessible() && l.Status != LinkBlocked
+}
@@ -0,0 +1,22 @@
+package models
+
+// FormKind is what an authentication form is for
+type FormKind string
+
+const (
+	FormLogin         FormKind = "login"
+	FormSignup        FormKind = "signup"
+	FormPasswordReset FormKind = "password_reset"
+)
+
+// AuthForm is a form, or a group of inputs and buttons outside a form,
+// that looks like it is used to log in, sign up or reset a password
+type AuthForm struct {
+	Kind FormKind `json:"kind"`
+	// Confidence is between 0 and 1
+	Confidence float64 `json:"confidence"`
+	// Signals are the reasons for the kind, like "password_input" or "button:Sign in"
+	Signals []string `json:"signals"`
+	// Path locates the form in the DOM
+	Path string `json:"path,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// Metadata is what search engines and social networks read from a page's head
+type Metadata struct {
+	Description string `json:"description,omitempty"`
+	// Robots are the lowercase directives of the robots meta tags, like "noindex"
+	Robots     []string          `json:"robots,omitempty"`
+	Canonical  string            `json:"canonical,omitempty"`
+	Alternates []Alternate       `json:"alternates,omitempty"`
+	OpenGraph  map[string]string `json:"open_graph,omitempty"`
+	Twitter    map[string]string `json:"twitter,omitempty"`
+	Viewport   string            `json:"viewport,omitempty"`
+	Charset    string            `json:"charset,omitempty"`
+	Lang       string            `json:"lang,omitempty"`
+	Icons      []Icon            `json:"icons,omitempty"`
+	Issues     []MetadataIssue   `json:"issues,omitempty"`
+}
+
+// Alternate is a translation of the page from <link rel="alternate" hreflang>
+type Alternate struct {
+	Hreflang string `json:"hreflang"`
+	URL      string `json:"url"`
+}
+
+// Icon is a favicon, apple-touch-icon or mask-icon
+type Icon struct {
+	Rel   string `json:"rel"`
+	URL   string `json:"url"`
+	Sizes string `json:"sizes,omitempty"`
+}
+
+// MetadataIssue is a failed metadata check, like a title that is too long
+type MetadataIssue struct {
+	// Field is the checked field, like "title" or "robots"
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
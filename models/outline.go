@@ -0,0 +1,24 @@
+package models
+
+// Outline is the tree of a page's headings
+type Outline struct {
+	Headings []Heading      `json:"headings,omitempty"`
+	Issues   []HeadingIssue `json:"issues,omitempty"`
+}
+
+// Heading is an h1 to h6 with the headings below it until the next heading
+// of the same or a higher level
+type Heading struct {
+	Level    int       `json:"level"`
+	Text     string    `json:"text"`
+	Path     string    `json:"path"`
+	Children []Heading `json:"children,omitempty"`
+}
+
+// HeadingIssue is a failed structure check. Path is empty for checks about
+// the whole page, like a missing h1.
+type HeadingIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+}
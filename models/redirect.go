@@ -0,0 +1,8 @@
+package models
+
+// Redirect is one hop of a redirect chain
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
@@ -1,17 +1,45 @@
 package models
 
+import "time"
+
 type CrawlResult struct {
-	URL               string
-	StatusCode        int
-	Status            string
-	Title             string
-	HTMLVersion       string
-	DocType           string
-	Headings          map[string]int
-	HasLoginForm      bool
-	InternalLinks     int
-	ExternalLinks     int
-	InaccessibleLinks int
-	Error             string
-	Success           bool
+	URL              string                 `json:"url"`
+	CrawledAt        time.Time              `json:"crawled_at"`
+	Depth            int                    `json:"depth"`
+	StatusCode       int                    `json:"status_code"`
+	Status           string                 `json:"status"`
+	FinalURL         string                 `json:"final_url"`
+	Redirects        []Redirect             `json:"redirects,omitempty"`
+	RedirectLoop     bool                   `json:"redirect_loop,omitempty"`
+	InsecureRedirect bool                   `json:"insecure_redirect,omitempty"`
+	Attempts         []Attempt              `json:"attempts,omitempty"`
+	Rendered         bool                   `json:"rendered"`
+	RenderError      string                 `json:"render_error,omitempty"`
+	Title            string                 `json:"title"`
+	HTMLVersion      string                 `json:"html_version"`
+	DocType          string                 `json:"doctype"`
+	Metadata         Metadata               `json:"metadata"`
+	StructuredData   StructuredData         `json:"structured_data"`
+	Accessibility    []AccessibilityFinding `json:"accessibility,omitempty"`
+	Findings         []Finding              `json:"findings,omitempty"`
+	// AnalyzerErrors are the analyzers that failed, by name
+	AnalyzerErrors map[string]string `json:"analyzer_errors,omitempty"`
+	Headings       map[string]int    `json:"headings"`
+	Outline        Outline           `json:"outline"`
+	HasLoginForm   bool              `json:"has_login_form"`
+	AuthForms      []AuthForm        `json:"auth_forms,omitempty"`
+	Forms          []Form            `json:"forms,omitempty"`
+	// InternalLinks, ExternalLinks and OtherLinks count links to other pages
+	InternalLinks     int                  `json:"internal_links"`
+	ExternalLinks     int                  `json:"external_links"`
+	InaccessibleLinks int                  `json:"inaccessible_links"`
+	OtherLinks        map[LinkCategory]int `json:"other_links,omitempty"`
+	// Resources counts the other links, like stylesheets and images, by resource type
+	Resources       map[ResourceType]int `json:"resources,omitempty"`
+	MixedContent    int                  `json:"mixed_content,omitempty"`
+	Links           []Link               `json:"links"`
+	BlockedByRobots bool                 `json:"blocked_by_robots"`
+	Analyzed        bool                 `json:"analyzed"`
+	Error           string               `json:"error,omitempty"`
+	Success         bool                 `json:"success"`
 }
@@ -0,0 +1,23 @@
+package models
+
+type SiteSummary struct {
+	PagesCrawled       int `json:"pages_crawled"`
+	PagesFailed        int `json:"pages_failed"`
+	PagesBlocked       int `json:"pages_blocked"`
+	MaxDepthReached    int `json:"max_depth_reached"`
+	InternalLinks      int `json:"internal_links"`
+	ExternalLinks      int `json:"external_links"`
+	InaccessibleLinks  int `json:"inaccessible_links"`
+	PagesWithLoginForm int `json:"pages_with_login_form"`
+	// PagesWithAccessibilityIssues counts pages with at least one accessibility finding
+	PagesWithAccessibilityIssues int `json:"pages_with_accessibility_issues"`
+	// Accessibility counts the findings of each rule, the most severe first
+	Accessibility []AccessibilityRuleSummary `json:"accessibility,omitempty"`
+	Truncated     bool                       `json:"truncated"`
+}
+
+type SiteResult struct {
+	SeedURL string        `json:"seed_url"`
+	Pages   []CrawlResult `json:"pages"`
+	Summary SiteSummary   `json:"summary"`
+}
@@ -0,0 +1,41 @@
+package models
+
+// DataFormat is the syntax structured data was embedded in
+type DataFormat string
+
+const (
+	FormatJSONLD    DataFormat = "json-ld"
+	FormatMicrodata DataFormat = "microdata"
+	FormatRDFa      DataFormat = "rdfa"
+)
+
+// StructuredData is the JSON-LD, Microdata and RDFa on a page
+type StructuredData struct {
+	Items []DataItem `json:"items,omitempty"`
+	// Types are the schema.org types of all items, nested ones included, each once and sorted
+	Types  []string              `json:"types,omitempty"`
+	Errors []StructuredDataError `json:"errors,omitempty"`
+}
+
+// DataItem is one thing described by structured data, like a Product. Types
+// from schema.org are short names like "Product", others are full URLs.
+type DataItem struct {
+	Format     DataFormat             `json:"format"`
+	Types      []string               `json:"types,omitempty"`
+	ID         string                 `json:"id,omitempty"`
+	Properties map[string][]DataValue `json:"properties,omitempty"`
+	Path       string                 `json:"path,omitempty"`
+}
+
+// DataValue is a property value, either text or a nested item
+type DataValue struct {
+	Text string    `json:"text,omitempty"`
+	Item *DataItem `json:"item,omitempty"`
+}
+
+// StructuredDataError is invalid JSON or an item that can't be understood
+type StructuredDataError struct {
+	Format  DataFormat `json:"format"`
+	Message string     `json:"message"`
+	Path    string     `json:"path,omitempty"`
+}
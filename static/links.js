@@ -0,0 +1,56 @@
+// Filtering and sorting for the links table on the result page
+(function () {
+    const table = document.getElementById('links');
+    if (!table) {
+        return;
+    }
+
+    const body = table.tBodies[0];
+    const search = document.getElementById('link-search');
+    const category = document.getElementById('link-category');
+    const resource = document.getElementById('link-resource');
+    const broken = document.getElementById('link-broken');
+    const nofollow = document.getElementById('link-nofollow');
+    const mixed = document.getElementById('link-mixed');
+
+    function filter() {
+        const query = search.value.trim().toLowerCase();
+        for (const row of body.rows) {
+            const visible =
+                (!query || row.textContent.toLowerCase().includes(query)) &&
+                (!category.value || row.dataset.category === category.value) &&
+                (!resource.value || row.dataset.resource === resource.value) &&
+                (!broken.checked || row.dataset.broken === '1') &&
+                (!nofollow.checked || row.dataset.rel.includes(' nofollow ')) &&
+                (!mixed.checked || row.dataset.mixed === '1');
+            row.hidden = !visible;
+        }
+    }
+
+    for (const input of [search, category, resource, broken, nofollow, mixed]) {
+        input.addEventListener('input', filter);
+    }
+
+    table.tHead.querySelectorAll('th').forEach(function (th, column) {
+        th.classList.add('sortable');
+        th.addEventListener('click', function () {
+            const ascending = th.dataset.order !== 'asc';
+            table.tHead.querySelectorAll('th').forEach(function (other) {
+                delete other.dataset.order;
+            });
+            th.dataset.order = ascending ? 'asc' : 'desc';
+
+            const numeric = th.dataset.sort === 'number';
+            const rows = Array.from(body.rows);
+            rows.sort(function (a, b) {
+                const x = a.cells[column].textContent.trim();
+                const y = b.cells[column].textContent.trim();
+                const order = numeric ? Number(x) - Number(y) : x.localeCompare(y);
+                return ascending ? order : -order;
+            });
+            rows.forEach(function (row) {
+                body.appendChild(row);
+            });
+        });
+    });
+})();
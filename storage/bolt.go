@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"go-webcrawler/models"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	recordsBucket = []byte("records")
+	// byURLBucket indexes record IDs by URL and crawl time: url \x00 unix-nanos -> id
+	byURLBucket = []byte("by_url")
+	// metaBucket holds the schema version, see Migrate
+	metaBucket = []byte("meta")
+	versionKey = []byte("version")
+)
+
+// schemaVersion 1 stores canonical URLs, before that they were kept as typed
+const schemaVersion = 1
+
+// CanonicalURL maps a URL to the form crawls are stored under. The store
+// doesn't know the crawler's rules, so Migrate is handed them
+type CanonicalURL func(string) string
+
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{recordsBucket, byURLBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Save(result models.CrawlResult) (Record, error) {
+	var record Record
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(recordsBucket)
+
+		seq, err := records.NextSequence()
+		if err != nil {
+			return err
+		}
+		record = newRecord(strconv.FormatUint(seq, 10), result)
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if err := records.Put([]byte(record.ID), data); err != nil {
+			return err
+		}
+
+		return tx.Bucket(byURLBucket).Put(urlIndexKey(record.URL, record.CrawledAt, record.ID), []byte(record.ID))
+	})
+
+	return record, err
+}
+
+func (s *BoltStore) Get(id string) (Record, error) {
+	var record Record
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(recordsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &record)
+	})
+
+	return record, err
+}
+
+func (s *BoltStore) Find(q Query) ([]Record, error) {
+	var found []Record
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(recordsBucket)
+
+		collect := func(id []byte) error {
+			var record Record
+			if err := json.Unmarshal(records.Get(id), &record); err != nil {
+				return err
+			}
+			if q.matches(record) {
+				found = append(found, record)
+			}
+			return nil
+		}
+
+		if q.URL == "" {
+			return records.ForEach(func(k, _ []byte) error {
+				return collect(k)
+			})
+		}
+
+		prefix := append([]byte(q.URL), 0)
+		c := tx.Bucket(byURLBucket).Cursor()
+		for k, id := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, id = c.Next() {
+			if err := collect(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return q.apply(found), nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// Migrate brings a database written by an older version up to
+// schemaVersion. Call it once after OpenBoltStore, before serving
+func (s *BoltStore) Migrate(canonical CanonicalURL) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		version := 0
+		if data := meta.Get(versionKey); data != nil {
+			version, _ = strconv.Atoi(string(data))
+		}
+
+		if version < 1 {
+			if err := canonicalizeURLs(tx, canonical); err != nil {
+				return err
+			}
+		}
+
+		return meta.Put(versionKey, []byte(strconv.Itoa(schemaVersion)))
+	})
+}
+
+// canonicalizeURLs moves records stored under the URL as it was typed, like
+// https://Doruk.com, to the canonical URL new crawls are stored under, so
+// they share one history
+func canonicalizeURLs(tx *bolt.Tx, canonicalURL CanonicalURL) error {
+	records := tx.Bucket(recordsBucket)
+	index := tx.Bucket(byURLBucket)
+
+	type move struct {
+		oldKey []byte
+		record Record
+	}
+	var moves []move
+
+	err := index.ForEach(func(k, id []byte) error {
+		var record Record
+		if err := json.Unmarshal(records.Get(id), &record); err != nil {
+			return err
+		}
+		canonical := canonicalURL(record.URL)
+		if canonical == record.URL {
+			return nil
+		}
+		record.URL = canonical
+		record.Result.URL = canonical
+		moves = append(moves, move{oldKey: bytes.Clone(k), record: record})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keys can't be changed while ForEach runs
+	for _, m := range moves {
+		data, err := json.Marshal(m.record)
+		if err != nil {
+			return err
+		}
+		if err := records.Put([]byte(m.record.ID), data); err != nil {
+			return err
+		}
+		if err := index.Delete(m.oldKey); err != nil {
+			return err
+		}
+		if err := index.Put(urlIndexKey(m.record.URL, m.record.CrawledAt, m.record.ID), []byte(m.record.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func urlIndexKey(url string, crawledAt time.Time, id string) []byte {
+	key := make([]byte, 0, len(url)+1+8+len(id))
+	key = append(key, url...)
+	key = append(key, 0)
+	key = binary.BigEndian.AppendUint64(key, uint64(crawledAt.UnixNano()))
+	return append(key, id...)
+}
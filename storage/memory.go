@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"go-webcrawler/models"
+	"strconv"
+	"sync"
+)
+
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records []Record
+	nextID  uint64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Save(result models.CrawlResult) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	record := newRecord(strconv.FormatUint(s.nextID, 10), result)
+	s.records = append(s.records, record)
+	return record, nil
+}
+
+func (s *MemoryStore) Get(id string) (Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, record := range s.records {
+		if record.ID == id {
+			return record, nil
+		}
+	}
+	return Record{}, ErrNotFound
+}
+
+func (s *MemoryStore) Find(q Query) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []Record
+	for _, record := range s.records {
+		if q.matches(record) {
+			records = append(records, record)
+		}
+	}
+
+	return q.apply(records), nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"errors"
+	"go-webcrawler/models"
+	"sort"
+	"time"
+)
+
+var ErrNotFound = errors.New("crawl record not found")
+
+type Record struct {
+	ID        string             `json:"id"`
+	URL       string             `json:"url"`
+	CrawledAt time.Time          `json:"crawled_at"`
+	Result    models.CrawlResult `json:"result"`
+}
+
+type Query struct {
+	URL   string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+type Store interface {
+	Save(result models.CrawlResult) (Record, error)
+	Get(id string) (Record, error)
+	// Find returns the records matching q, newest first
+	Find(q Query) ([]Record, error)
+	Close() error
+}
+
+func (q Query) matches(r Record) bool {
+	if q.URL != "" && r.URL != q.URL {
+		return false
+	}
+	if !q.Since.IsZero() && r.CrawledAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && r.CrawledAt.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+// apply sorts records newest first and cuts them to the limit
+func (q Query) apply(records []Record) []Record {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CrawledAt.After(records[j].CrawledAt)
+	})
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+	}
+	return records
+}
+
+func newRecord(id string, result models.CrawlResult) Record {
+	crawledAt := result.CrawledAt
+	if crawledAt.IsZero() {
+		crawledAt = time.Now()
+	}
+
+	return Record{
+		ID:        id,
+		URL:       result.URL,
+		CrawledAt: crawledAt.UTC(),
+		Result:    result,
+	}
+}
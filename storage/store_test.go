@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"errors"
+	"go-webcrawler/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	bolt, err := OpenBoltStore(filepath.Join(t.TempDir(), "crawls.db"))
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return map[string]Store{
+		"Memory": NewMemoryStore(),
+		"Bolt":   bolt,
+	}
+}
+
+func TestStore(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	results := []models.CrawlResult{
+		{URL: "https://doruk.com", Title: "First", CrawledAt: base},
+		{URL: "https://example.com", Title: "Other", CrawledAt: base.Add(time.Hour)},
+		{URL: "https://doruk.com", Title: "Second", CrawledAt: base.Add(2 * time.Hour)},
+		{URL: "https://doruk.com/about", Title: "About", CrawledAt: base.Add(3 * time.Hour)},
+		{URL: "https://doruk.com", Title: "Third", CrawledAt: base.Add(4 * time.Hour)},
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"All", Query{}, []string{"Third", "About", "Second", "Other", "First"}},
+		{"By URL", Query{URL: "https://doruk.com"}, []string{"Third", "Second", "First"}},
+		{"Since", Query{URL: "https://doruk.com", Since: base.Add(time.Hour)}, []string{"Third", "Second"}},
+		{"Until", Query{URL: "https://doruk.com", Until: base.Add(2 * time.Hour)}, []string{"Second", "First"}},
+		{"Range", Query{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)}, []string{"About", "Second", "Other"}},
+		{"Limit", Query{URL: "https://doruk.com", Limit: 2}, []string{"Third", "Second"}},
+		{"Unknown URL", Query{URL: "https://unknown.com"}, nil},
+	}
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			var saved []Record
+			for _, result := range results {
+				record, err := store.Save(result)
+				if err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+				saved = append(saved, record)
+			}
+
+			got, err := store.Get(saved[2].ID)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if got.Result.Title != "Second" || !got.CrawledAt.Equal(base.Add(2*time.Hour)) {
+				t.Errorf("Get returned %q at %v", got.Result.Title, got.CrawledAt)
+			}
+
+			if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					records, err := store.Find(tt.query)
+					if err != nil {
+						t.Fatalf("Find failed: %v", err)
+					}
+
+					if len(records) != len(tt.expected) {
+						t.Fatalf("Expected %d records, got %d", len(tt.expected), len(records))
+					}
+					for i, title := range tt.expected {
+						if records[i].Result.Title != title {
+							t.Errorf("Expected record %d to be %q, got %q", i, title, records[i].Result.Title)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestBoltStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawls.db")
+
+	store, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	record, err := store.Save(models.CrawlResult{URL: "https://doruk.com", Title: "Kept"})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	store.Close()
+
+	store, err = OpenBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen bolt store: %v", err)
+	}
+	defer store.Close()
+
+	got, err := store.Get(record.ID)
+	if err != nil {
+		t.Fatalf("Get after reopen failed: %v", err)
+	}
+	if got.Result.Title != "Kept" {
+		t.Errorf("Expected title 'Kept', got %q", got.Result.Title)
+	}
+	if got.CrawledAt.IsZero() {
+		t.Error("Expected crawl time to be set on save")
+	}
+}
+
+func TestBoltStore_CanonicalizeURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawls.db")
+
+	store, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, url := range []string{"https://doruk.com", "https://Doruk.COM:443", "https://doruk.com/"} {
+		if _, err := store.Save(models.CrawlResult{URL: url, CrawledAt: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	// Pretend the records were written before URLs were canonicalized
+	store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Delete(versionKey)
+	})
+	store.Close()
+
+	store, err = OpenBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen bolt store: %v", err)
+	}
+	defer store.Close()
+	// Stands in for crawler.NormalizeURL, which the store doesn't import
+	canonical := func(url string) string {
+		switch url {
+		case "https://doruk.com", "https://Doruk.COM:443":
+			return "https://doruk.com/"
+		}
+		return url
+	}
+	if err := store.Migrate(canonical); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	records, err := store.Find(Query{URL: "https://doruk.com/"})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected all 3 crawls in one history, got %d", len(records))
+	}
+	for _, record := range records {
+		if record.URL != "https://doruk.com/" || record.Result.URL != "https://doruk.com/" {
+			t.Errorf("Expected the canonical URL, got %q and %q", record.URL, record.Result.URL)
+		}
+	}
+	if records, _ := store.Find(Query{URL: "https://doruk.com"}); len(records) != 0 {
+		t.Errorf("Expected nothing left under the typed URL, got %d records", len(records))
+	}
+}